@@ -0,0 +1,148 @@
+package rbtree
+
+// seek finds leftmost node >= key, or > key if not inclusive
+func (t *RBTree) seek(key Comparable, inclusive bool) *RBNode {
+	found := t.Nil
+	for n := t.root; n != t.Nil; {
+		var ok bool
+		if inclusive {
+			ok = key.LessEqual(n.bag)
+		} else {
+			ok = !n.bag.LessEqual(key)
+		}
+		if ok {
+			found = n
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return found
+}
+
+// seekBack finds rightmost node <= key, or < key if not inclusive
+func (t *RBTree) seekBack(key Comparable, inclusive bool) *RBNode {
+	found := t.Nil
+	for n := t.root; n != t.Nil; {
+		var ok bool
+		if inclusive {
+			ok = n.bag.LessEqual(key)
+		} else {
+			ok = !key.LessEqual(n.bag)
+		}
+		if ok {
+			found = n
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return found
+}
+
+// FloorNode returns the largest node <= key, t.Nil if not exists. for
+// dupable tree, Floor and Lower give the rightmost of equal keys, which
+// is the latest inserted
+func (t *RBTree) FloorNode(key Comparable) *RBNode {
+	return t.seekBack(key, true)
+}
+
+// LowerNode returns the largest node < key, t.Nil if not exists
+func (t *RBTree) LowerNode(key Comparable) *RBNode {
+	return t.seekBack(key, false)
+}
+
+// CeilingNode returns the smallest node >= key, t.Nil if not exists. for
+// dupable tree, Ceiling and Higher give the leftmost of equal keys, which
+// is the earliest inserted
+func (t *RBTree) CeilingNode(key Comparable) *RBNode {
+	return t.seek(key, true)
+}
+
+// HigherNode returns the smallest node > key, t.Nil if not exists
+func (t *RBTree) HigherNode(key Comparable) *RBNode {
+	return t.seek(key, false)
+}
+
+func (t *RBTree) Floor(key Comparable) Comparable {
+	return t.FloorNode(key).bag
+}
+
+func (t *RBTree) Lower(key Comparable) Comparable {
+	return t.LowerNode(key).bag
+}
+
+func (t *RBTree) Ceiling(key Comparable) Comparable {
+	return t.CeilingNode(key).bag
+}
+
+func (t *RBTree) Higher(key Comparable) Comparable {
+	return t.HigherNode(key).bag
+}
+
+func (t *Tree[K, V]) seek(key K, inclusive bool) *Node[K, V] {
+	found := t.Nil
+	for n := t.root; n != t.Nil; {
+		c := t.cmp(key, n.bag.Key)
+		if c < 0 || (c == 0 && inclusive) {
+			found = n
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return found
+}
+
+func (t *Tree[K, V]) seekBack(key K, inclusive bool) *Node[K, V] {
+	found := t.Nil
+	for n := t.root; n != t.Nil; {
+		c := t.cmp(key, n.bag.Key)
+		if c > 0 || (c == 0 && inclusive) {
+			found = n
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return found
+}
+
+func (t *Tree[K, V]) FloorNode(key K) *Node[K, V] {
+	return t.seekBack(key, true)
+}
+
+func (t *Tree[K, V]) LowerNode(key K) *Node[K, V] {
+	return t.seekBack(key, false)
+}
+
+func (t *Tree[K, V]) CeilingNode(key K) *Node[K, V] {
+	return t.seek(key, true)
+}
+
+func (t *Tree[K, V]) HigherNode(key K) *Node[K, V] {
+	return t.seek(key, false)
+}
+
+func (t *Tree[K, V]) Floor(key K) (K, V, bool) {
+	return t.entry(t.FloorNode(key))
+}
+
+func (t *Tree[K, V]) Lower(key K) (K, V, bool) {
+	return t.entry(t.LowerNode(key))
+}
+
+func (t *Tree[K, V]) Ceiling(key K) (K, V, bool) {
+	return t.entry(t.CeilingNode(key))
+}
+
+func (t *Tree[K, V]) Higher(key K) (K, V, bool) {
+	return t.entry(t.HigherNode(key))
+}
+
+func (t *Tree[K, V]) entry(n *Node[K, V]) (key K, value V, ok bool) {
+	if n != t.Nil {
+		return n.bag.Key, n.bag.Value, true
+	}
+	return
+}
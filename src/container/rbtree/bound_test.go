@@ -0,0 +1,90 @@
+package rbtree
+
+import (
+	"testing"
+)
+
+func TestFloorCeiling(t *testing.T) {
+	tree := NewRBTree(false)
+	for i := 0; i < 20; i += 2 {
+		tree.Insert(MyInt(i))
+	}
+
+	cases := []struct {
+		key                           int
+		floor, lower, ceiling, higher Comparable
+	}{
+		{-1, nil, nil, MyInt(0), MyInt(0)},
+		{0, MyInt(0), nil, MyInt(0), MyInt(2)},
+		{5, MyInt(4), MyInt(4), MyInt(6), MyInt(6)},
+		{6, MyInt(6), MyInt(4), MyInt(6), MyInt(8)},
+		{18, MyInt(18), MyInt(16), MyInt(18), nil},
+		{19, MyInt(18), MyInt(18), nil, nil},
+	}
+
+	for _, c := range cases {
+		key := MyInt(c.key)
+		if v := tree.Floor(key); v != c.floor {
+			t.Errorf("floor of %d expect %v, really %v", c.key, c.floor, v)
+		}
+		if v := tree.Lower(key); v != c.lower {
+			t.Errorf("lower of %d expect %v, really %v", c.key, c.lower, v)
+		}
+		if v := tree.Ceiling(key); v != c.ceiling {
+			t.Errorf("ceiling of %d expect %v, really %v", c.key, c.ceiling, v)
+		}
+		if v := tree.Higher(key); v != c.higher {
+			t.Errorf("higher of %d expect %v, really %v", c.key, c.higher, v)
+		}
+	}
+
+	if n := NewRBTree(false).FloorNode(MyInt(1)); n == nil || n.Bag() != nil {
+		t.Error("floor of empty tree should be sentinel")
+	}
+}
+
+func TestFloorCeilingDupable(t *testing.T) {
+	tree := NewRBTree(true)
+	for _, item := range []int{0, 0, 1, 2, 3, 4, 4, 4, 5, 6, 7, 7, 8, 9, 10} {
+		tree.Insert(MyInt(item))
+	}
+
+	// ceiling/higher must be leftmost of the run, floor/lower rightmost
+	if n := tree.CeilingNode(MyInt(4)); tree.PrevNode(n).Bag() != MyInt(3) {
+		t.Error("ceiling should be leftmost of equal keys")
+	}
+	if n := tree.HigherNode(MyInt(3)); n.Bag() != MyInt(4) || tree.PrevNode(n).Bag() != MyInt(3) {
+		t.Error("higher should be leftmost of equal keys")
+	}
+	if n := tree.FloorNode(MyInt(4)); tree.NextNode(n).Bag() != MyInt(5) {
+		t.Error("floor should be rightmost of equal keys")
+	}
+	if n := tree.LowerNode(MyInt(5)); n.Bag() != MyInt(4) || tree.NextNode(n).Bag() != MyInt(5) {
+		t.Error("lower should be rightmost of equal keys")
+	}
+	if n := tree.FloorNode(MyInt(0)); tree.NextNode(n).Bag() != MyInt(1) || tree.Rank(MyInt(0)) != 0 {
+		t.Error("floor should be rightmost of equal keys")
+	}
+}
+
+func TestTreeFloorCeiling(t *testing.T) {
+	tree := NewTree[float64, string](false)
+	tree.Insert(1.5, "a")
+	tree.Insert(3.5, "b")
+
+	if k, v, ok := tree.Floor(2); !ok || k != 1.5 || v != "a" {
+		t.Errorf("floor expect 1.5, really %v", k)
+	}
+	if k, _, ok := tree.Ceiling(2); !ok || k != 3.5 {
+		t.Errorf("ceiling expect 3.5, really %v", k)
+	}
+	if _, _, ok := tree.Lower(1.5); ok {
+		t.Error("lower of min should not exist")
+	}
+	if _, _, ok := tree.Higher(3.5); ok {
+		t.Error("higher of max should not exist")
+	}
+	if k, _, ok := tree.Higher(1.5); !ok || k != 3.5 {
+		t.Errorf("higher expect 3.5, really %v", k)
+	}
+}
@@ -0,0 +1,87 @@
+package rbtree
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+)
+
+// build replaces content of t with a balanced tree of sorted bags in O(n),
+// the middle bag of each range becomes the root of it, so leaves are on the
+// deepest two levels, nodes of the deepest level are colored red to keep
+// black height equal on every path
+func (t *tree[T]) build(bags []T) {
+	t.root = t.Nil
+	t.size = len(bags)
+	if len(bags) == 0 {
+		return
+	}
+	deepest := bits.Len(uint(len(bags))) - 1
+	t.root = t.buildRange(bags, 0, deepest)
+	t.root.p = t.Nil
+	t.root.color = Black
+}
+
+func (t *tree[T]) buildRange(bags []T, depth, deepest int) *node[T] {
+	if len(bags) == 0 {
+		return t.Nil
+	}
+	mid := len(bags) / 2
+	color := Black
+	if depth == deepest {
+		color = Red
+	}
+	n := t.newNode(bags[mid], color)
+	if n.left = t.buildRange(bags[:mid], depth+1, deepest); n.left != t.Nil {
+		n.left.p = n
+	}
+	if n.right = t.buildRange(bags[mid+1:], depth+1, deepest); n.right != t.Nil {
+		n.right.p = n
+	}
+	t.update(n)
+	return n
+}
+
+// NewRBTreeFromSorted builds a tree from bags in ascending order in O(n),
+// an error is returned if bags are out of order, or duplicate for
+// nondupable tree. bags is not retained
+func NewRBTreeFromSorted(bags []Comparable, dupable bool) (*RBTree, error) {
+	for i := 1; i < len(bags); i++ {
+		if err := checkSorted(bags[i-1], bags[i], i, dupable); err != nil {
+			return nil, err
+		}
+	}
+
+	t := NewRBTree(dupable)
+	t.build(bags)
+	return t, nil
+}
+
+// NewRBTreeFromSeq is like NewRBTreeFromSorted, but reads bags from seq
+func NewRBTreeFromSeq(seq iter.Seq[Comparable], dupable bool) (*RBTree, error) {
+	var bags []Comparable
+	for bag := range seq {
+		if l := len(bags); l > 0 {
+			if err := checkSorted(bags[l-1], bag, l, dupable); err != nil {
+				return nil, err
+			}
+		}
+		bags = append(bags, bag)
+	}
+
+	t := NewRBTree(dupable)
+	t.build(bags)
+	return t, nil
+}
+
+func checkSorted(prev, bag Comparable, i int, dupable bool) error {
+	switch Compare(prev, bag) {
+	case Greater:
+		return fmt.Errorf("bag %d out of order", i)
+	case Equal:
+		if !dupable {
+			return fmt.Errorf("duplicate bag %d for nondupable tree", i)
+		}
+	}
+	return nil
+}
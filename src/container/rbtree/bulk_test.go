@@ -0,0 +1,88 @@
+package rbtree
+
+import (
+	"slices"
+	"testing"
+)
+
+func sortedBags(items []int) (bags []Comparable) {
+	for _, item := range items {
+		bags = append(bags, MyInt(item))
+	}
+	return
+}
+
+func TestFromSorted(t *testing.T) {
+	for n := 0; n < 300; n++ {
+		items := make([]int, n)
+		for i := range items {
+			items[i] = i * 2
+		}
+		tree, err := NewRBTreeFromSorted(sortedBags(items), false)
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if err := tree.Verify(); err != nil {
+			t.Errorf("verify error of %d bags %s", n, err.Error())
+		}
+		if got := collect(tree.All()); !slices.Equal(got, items) {
+			t.Errorf("not in order, %v", got)
+		}
+
+		if n == 0 {
+			continue
+		}
+		// parent links must be right for later modification
+		tree.Insert(MyInt(1))
+		tree.Delete(MyInt(0), false)
+		if err := tree.Verify(); err != nil {
+			t.Errorf("verify error after modification %s", err.Error())
+		}
+		if tree.Len() != n {
+			t.Errorf("expect len %d, really %d", n, tree.Len())
+		}
+	}
+}
+
+func TestFromSortedDupable(t *testing.T) {
+	items := []int{1, 1, 2, 3, 3, 3, 4, 5, 5}
+	tree, err := NewRBTreeFromSeq(slices.Values(sortedBags(items)), true)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+	if got := collect(tree.All()); !slices.Equal(got, items) {
+		t.Errorf("not in order, %v", got)
+	}
+	if len(tree.Find(MyInt(3))) != 3 {
+		t.Errorf("expect 3 of 3, really %d", len(tree.Find(MyInt(3))))
+	}
+
+	if _, err := NewRBTreeFromSorted(sortedBags(items), false); err == nil {
+		t.Error("expect error for duplicate bags of nondupable tree")
+	}
+	if _, err := NewRBTreeFromSeq(slices.Values(sortedBags([]int{1, 3, 2})), true); err == nil {
+		t.Error("expect error for bags out of order")
+	}
+}
+
+func BenchmarkFromSorted(b *testing.B) {
+	bags := make([]Comparable, 100000)
+	for i := range bags {
+		bags[i] = MyInt(i)
+	}
+	for i := 0; i < b.N; i++ {
+		NewRBTreeFromSorted(bags, false)
+	}
+}
+
+func BenchmarkInsertSorted(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		tree := NewRBTree(false)
+		for j := 0; j < 100000; j++ {
+			tree.Insert(MyInt(j))
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package rbtree
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// format of an encoded tree, integers are uvarint unless noted:
+//
+//	magic "RBT", version byte, flags byte (bit 0 for dupable)
+//	size
+//	size times: length of bag, encoded bag
+//	crc32 (IEEE) of all above, 4 bytes big endian
+//
+// bags are written in order, so decoding builds the tree in O(n)
+
+const (
+	encodeMagic   = "RBT"
+	encodeVersion = 1
+	flagDupable   = 1
+)
+
+// Codec encodes bags of a tree to bytes and back
+type Codec interface {
+	Encode(bag Comparable) ([]byte, error)
+	Decode(data []byte) (Comparable, error)
+}
+
+// BinaryCodec encodes bags which implement encoding.BinaryMarshaler, New
+// returns an empty bag which implements encoding.BinaryUnmarshaler
+type BinaryCodec struct {
+	New func() Comparable
+}
+
+func (c BinaryCodec) Encode(bag Comparable) ([]byte, error) {
+	m, ok := bag.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a BinaryMarshaler", bag)
+	}
+	return m.MarshalBinary()
+}
+
+func (c BinaryCodec) Decode(data []byte) (Comparable, error) {
+	bag := c.New()
+	u, ok := bag.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a BinaryUnmarshaler", bag)
+	}
+	if err := u.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return bag, nil
+}
+
+// Encode writes bags of t in order to w
+func (t *RBTree) Encode(w io.Writer, c Codec) error {
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(w)
+	hw := io.MultiWriter(bw, crc)
+
+	var flags byte
+	if t.dupable {
+		flags |= flagDupable
+	}
+	buf := append([]byte(encodeMagic), encodeVersion, flags)
+	buf = binary.AppendUvarint(buf, uint64(t.size))
+	if _, err := hw.Write(buf); err != nil {
+		return err
+	}
+
+	i := 0
+	for bag := range t.All() {
+		data, err := c.Encode(bag)
+		if err != nil {
+			return fmt.Errorf("encode bag %d: %w", i, err)
+		}
+		buf = binary.AppendUvarint(buf[:0], uint64(len(data)))
+		if _, err := hw.Write(buf); err != nil {
+			return err
+		}
+		if _, err := hw.Write(data); err != nil {
+			return err
+		}
+		i++
+	}
+
+	if _, err := bw.Write(crc.Sum(nil)); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// exactReader reads a byte at a time for ReadByte, so nothing past what
+// is asked for is taken from r
+type exactReader struct {
+	io.Reader
+	buf [1]byte
+}
+
+func (r *exactReader) ReadByte() (byte, error) {
+	if _, err := io.ReadFull(r.Reader, r.buf[:]); err != nil {
+		return 0, err
+	}
+	return r.buf[0], nil
+}
+
+// crcReader hashes every byte read through it
+type crcReader struct {
+	r   byteReader
+	crc hash.Hash32
+}
+
+func (r *crcReader) ReadByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == nil {
+		r.crc.Write([]byte{b})
+	}
+	return b, err
+}
+
+func (r *crcReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.crc.Write(p[:n])
+	return n, err
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// DecodeRBTree reads a tree written by Encode, bags are checked to be in
+// order, and the checksum is verified before the tree is returned.
+//
+// nothing past the checksum is read from r, so a tree can be embedded in a
+// larger stream. if r is not an io.ByteReader it is read a byte at a time
+// for varints, wrap it in a bufio.Reader and keep reading from that one
+// for speed
+func DecodeRBTree(r io.Reader, c Codec) (*RBTree, error) {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = &exactReader{Reader: r}
+	}
+	cr := &crcReader{r: br, crc: crc32.NewIEEE()}
+
+	header := make([]byte, len(encodeMagic)+2)
+	if _, err := io.ReadFull(cr, header); err != nil {
+		return nil, fmt.Errorf("read header: %w", unexpected(err))
+	}
+	if string(header[:len(encodeMagic)]) != encodeMagic {
+		return nil, errors.New("not an encoded tree, bad magic")
+	}
+	if v := header[len(encodeMagic)]; v != encodeVersion {
+		return nil, fmt.Errorf("unsupported version %d", v)
+	}
+	flags := header[len(encodeMagic)+1]
+	if flags&^flagDupable != 0 {
+		return nil, fmt.Errorf("unknown flags %#x", flags)
+	}
+	dupable := flags&flagDupable != 0
+
+	size, err := binary.ReadUvarint(cr)
+	if err != nil {
+		return nil, fmt.Errorf("read size: %w", unexpected(err))
+	}
+
+	// size is not trusted before checksum, so do not allocate by it at once
+	bags := make([]Comparable, 0, min(size, 1<<16))
+	for i := 0; uint64(i) < size; i++ {
+		l, err := binary.ReadUvarint(cr)
+		if err != nil {
+			return nil, fmt.Errorf("read length of bag %d of %d: %w", i, size, unexpected(err))
+		}
+		data, err := io.ReadAll(io.LimitReader(cr, int64(min(l, 1<<62))))
+		if err != nil {
+			return nil, fmt.Errorf("read bag %d of %d: %w", i, size, err)
+		}
+		if uint64(len(data)) != l {
+			return nil, fmt.Errorf("read bag %d of %d: %w", i, size, io.ErrUnexpectedEOF)
+		}
+		bag, err := c.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode bag %d: %w", i, err)
+		}
+		if i > 0 {
+			if err := checkSorted(bags[i-1], bag, i, dupable); err != nil {
+				return nil, err
+			}
+		}
+		bags = append(bags, bag)
+	}
+
+	sum := cr.crc.Sum32()
+	trailer := make([]byte, 4)
+	if _, err := io.ReadFull(cr.r, trailer); err != nil {
+		return nil, fmt.Errorf("read checksum: %w", unexpected(err))
+	}
+	if binary.BigEndian.Uint32(trailer) != sum {
+		return nil, errors.New("checksum mismatch, stream is corrupted")
+	}
+
+	t := NewRBTree(dupable)
+	t.build(bags)
+	return t, nil
+}
@@ -0,0 +1,158 @@
+package rbtree
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type intCodec struct{}
+
+func (intCodec) Encode(bag Comparable) ([]byte, error) {
+	return binary.AppendVarint(nil, int64(bag.(MyInt))), nil
+}
+
+func (intCodec) Decode(data []byte) (Comparable, error) {
+	v, n := binary.Varint(data)
+	if n != len(data) {
+		return nil, errors.New("bad varint")
+	}
+	return MyInt(v), nil
+}
+
+func (a *pair) MarshalBinary() ([]byte, error) {
+	return append(binary.AppendVarint(nil, int64(a.k)), a.v...), nil
+}
+
+func (a *pair) UnmarshalBinary(data []byte) error {
+	k, n := binary.Varint(data)
+	if n <= 0 {
+		return errors.New("bad varint")
+	}
+	a.k, a.v = int(k), string(data[n:])
+	return nil
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, dupable := range []bool{false, true} {
+		tree, items := randomTree(500, 300, dupable)
+		var buf bytes.Buffer
+		if err := tree.Encode(&buf, intCodec{}); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		loaded, err := DecodeRBTree(&buf, intCodec{})
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		verifyItems(t, "decode", loaded, items)
+		if loaded.dupable != dupable {
+			t.Errorf("dupable flag lost")
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := NewRBTree(false).Encode(&buf, intCodec{}); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if loaded, err := DecodeRBTree(&buf, intCodec{}); err != nil || loaded.Len() != 0 {
+		t.Errorf("empty tree not decoded, %v", err)
+	}
+}
+
+func TestDecodeEmbedded(t *testing.T) {
+	a, itemsA := randomTree(100, 300, false)
+	b, itemsB := randomTree(100, 300, true)
+	var buf bytes.Buffer
+	a.Encode(&buf, intCodec{})
+	b.Encode(&buf, intCodec{})
+	buf.WriteString("TRAILING-DATA")
+	data := buf.Bytes()
+
+	// one is a ByteReader and read in place, the other is not
+	for _, r := range []io.Reader{bytes.NewReader(data), struct{ io.Reader }{bytes.NewReader(data)}} {
+		for _, items := range [][]int{itemsA, itemsB} {
+			loaded, err := DecodeRBTree(r, intCodec{})
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			verifyItems(t, "decode embedded", loaded, items)
+		}
+		if rest, _ := io.ReadAll(r); string(rest) != "TRAILING-DATA" {
+			t.Errorf("expect trailing data left, really %q", rest)
+		}
+	}
+}
+
+func TestBinaryCodec(t *testing.T) {
+	tree := NewRBTree(false)
+	for i, v := range []string{"c", "a", "", "b"} {
+		tree.Insert(&pair{i * 7 % 4, v})
+	}
+	codec := BinaryCodec{New: func() Comparable { return &pair{} }}
+
+	var buf bytes.Buffer
+	if err := tree.Encode(&buf, codec); err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	loaded, err := DecodeRBTree(&buf, codec)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	var got []pair
+	for bag := range loaded.All() {
+		got = append(got, *bag.(*pair))
+	}
+	if !slices.Equal(got, []pair{{0, "c"}, {1, "b"}, {2, ""}, {3, "a"}}) {
+		t.Errorf("not expected: %v", got)
+	}
+
+	ints := NewRBTree(false)
+	ints.Insert(MyInt(1))
+	if err := ints.Encode(io.Discard, codec); err == nil {
+		t.Error("expect error for bag not a BinaryMarshaler")
+	}
+}
+
+func TestDecodeCorrupted(t *testing.T) {
+	tree, _ := randomTree(50, 1000, false)
+	var buf bytes.Buffer
+	tree.Encode(&buf, intCodec{})
+	data := buf.Bytes()
+
+	for i := 0; i < len(data); i++ {
+		_, err := DecodeRBTree(bytes.NewReader(data[:i]), intCodec{})
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("truncated at %d, expect unexpected EOF, really %v", i, err)
+		}
+	}
+
+	for i := 0; i < len(data); i++ {
+		corrupted := slices.Clone(data)
+		corrupted[i] ^= 0x10
+		if _, err := DecodeRBTree(bytes.NewReader(corrupted), intCodec{}); err == nil {
+			t.Errorf("corrupted at %d, expect error", i)
+		}
+	}
+
+	for _, c := range []struct {
+		data   []byte
+		expect string
+	}{
+		{[]byte("XYZ\x01\x00\x00"), "bad magic"},
+		{[]byte("RBT\x02\x00\x00"), "unsupported version"},
+		{[]byte("RBT\x01\x04\x00"), "unknown flags"},
+		// 2, 1 out of order
+		{[]byte("RBT\x01\x00\x02\x01\x04\x01\x02"), "out of order"},
+		// 1, 1 duplicate for nondupable tree
+		{[]byte("RBT\x01\x00\x02\x01\x02\x01\x02"), "duplicate"},
+		{[]byte("RBT\x01\x00\x00\x00\x00\x00\x00"), "checksum mismatch"},
+	} {
+		if _, err := DecodeRBTree(bytes.NewReader(c.data), intCodec{}); err == nil || !strings.Contains(err.Error(), c.expect) {
+			t.Errorf("expect error contains %s, really %v", c.expect, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package rbtree
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+)
+
+// Entry is what a Tree node carries, returned by its Bag
+type Entry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+type Node[K, V any] = node[Entry[K, V]]
+
+// Tree is the type-parameterized counterpart of RBTree, keys are ordered by
+// a three-way compare function instead of Comparable, so no boxing is needed
+type Tree[K, V any] struct {
+	tree[Entry[K, V]]
+	cmp     func(a, b K) int
+	dupable bool
+}
+
+func NewTree[K cmp.Ordered, V any](dupable bool) *Tree[K, V] {
+	return NewTreeFunc[K, V](cmp.Compare[K], dupable)
+}
+
+// cmp(a, b) should return negative if a < b, zero if a == b, positive if a > b
+func NewTreeFunc[K, V any](cmp func(a, b K) int, dupable bool) *Tree[K, V] {
+	t := &Tree[K, V]{cmp: cmp, dupable: dupable}
+	t.init()
+	return t
+}
+
+func (t *Tree[K, V]) NewNode(key K, value V) *Node[K, V] {
+	return t.newNode(Entry[K, V]{Key: key, Value: value}, Red)
+}
+
+// InsertNode inserts n, which may be a node removed before, its links and
+// color are reset. n must not be in any tree
+func (t *Tree[K, V]) InsertNode(n *Node[K, V]) error {
+	if n == nil || !detached(n) {
+		return errors.New("node already in a tree")
+	}
+	parent := t.Nil
+	p := &t.root
+	for *p != t.Nil {
+		parent = *p
+		c := t.cmp(n.bag.Key, (*p).bag.Key)
+		// equal keys go right, so they are kept in insertion order
+		if c < 0 {
+			p = &((*p).left)
+		} else if c > 0 || t.dupable {
+			p = &((*p).right)
+		} else {
+			return errors.New("duplicate key for nondupable tree")
+		}
+	}
+	t.size += 1
+	n.p = parent
+	*p = n
+	n.left = t.Nil
+	n.right = t.Nil
+	n.color = Red
+	t.updateUp(n)
+	t.insertFix(n)
+	return nil
+}
+
+func (t *Tree[K, V]) Insert(key K, value V) error {
+	return t.InsertNode(t.NewNode(key, value))
+}
+
+// Put sets value of the first entry with key, or inserts one if there is
+// no such entry, it reports whether an entry is inserted
+func (t *Tree[K, V]) Put(key K, value V) (inserted bool) {
+	if n := t.seek(key, true); n != t.Nil && t.cmp(key, n.bag.Key) == 0 {
+		n.bag.Value = value
+		return false
+	}
+	t.InsertNode(t.NewNode(key, value))
+	return true
+}
+
+// SetValue replaces value of n in place, unlike the key, value has nothing
+// to do with where n is, so n stays where it is
+func (t *Tree[K, V]) SetValue(n *Node[K, V], value V) error {
+	if !t.Owns(n) {
+		return errors.New("node not in tree")
+	}
+	n.bag.Value = value
+	return nil
+}
+
+// Delete removes the first entry with key, which is the earliest inserted
+// for dupable tree, or all of them if all is set
+func (t *Tree[K, V]) Delete(key K, all bool) error {
+	if !t.dupable && all {
+		return errors.New("no need to delete all for nondupable tree")
+	}
+
+	nodes := t.FindNode(key)
+	if len(nodes) == 0 {
+		return errors.New("key not found")
+	}
+
+	if !all {
+		nodes = nodes[:1]
+	}
+	for _, node := range nodes {
+		t.deleteNode(node)
+	}
+	return nil
+}
+
+func (t *Tree[K, V]) Find(key K) (values []V) {
+	for _, n := range t.FindNode(key) {
+		values = append(values, n.bag.Value)
+	}
+	return
+}
+
+// FindNode returns nodes with key in order, which is insertion order for
+// dupable tree
+func (t *Tree[K, V]) FindNode(key K) (nodes []*Node[K, V]) {
+	for n := t.seek(key, true); n != t.Nil && t.cmp(key, n.bag.Key) == 0; n = t.NextNode(n) {
+		nodes = append(nodes, n)
+		if !t.dupable {
+			break
+		}
+	}
+	return
+}
+
+// Verify also checks keys are in order, strictly for nondupable tree
+func (t *Tree[K, V]) Verify() error {
+	if err := t.tree.Verify(); err != nil {
+		return err
+	}
+	i, prev := 0, t.Nil
+	for n := t.MinNode(); n != t.Nil; n = t.NextNode(n) {
+		if prev != t.Nil {
+			if c := t.cmp(prev.bag.Key, n.bag.Key); c > 0 || (c == 0 && !t.dupable) {
+				return fmt.Errorf("keys out of order at %d", i)
+			}
+		}
+		i, prev = i+1, n
+	}
+	return nil
+}
+
+func (t *Tree[K, V]) Min() (K, V, bool) {
+	return t.entry(t.MinNode())
+}
+
+func (t *Tree[K, V]) Max() (K, V, bool) {
+	return t.entry(t.MaxNode())
+}
@@ -0,0 +1,188 @@
+package rbtree
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTreeInsertFind(t *testing.T) {
+	tree := NewTree[int, string](false)
+	for i := 0; i < 1000; i++ {
+		if err := tree.Insert(i, strings.Repeat("x", i%7)); err != nil {
+			t.Errorf("unexpected error for key %d, %s", i, err.Error())
+		}
+		if err := tree.Verify(); err != nil {
+			t.Errorf("verify error %s", err.Error())
+		}
+	}
+
+	if tree.Len() != 1000 {
+		t.Errorf("size error, expect %d v.s. %d", 1000, tree.Len())
+	}
+
+	if err := tree.Insert(10, ""); err == nil {
+		t.Error("error expected for duplicate key of nondupable tree")
+	}
+	if tree.Len() != 1000 {
+		t.Errorf("size changed by failed insert, %d", tree.Len())
+	}
+
+	for i := -10; i < 1010; i++ {
+		vs := tree.Find(i)
+		if i < 0 || i >= 1000 {
+			if len(vs) > 0 {
+				t.Errorf("found nonexist %d: %v", i, vs)
+			}
+		} else if len(vs) != 1 || vs[0] != strings.Repeat("x", i%7) {
+			t.Errorf("value of %d not match, %v", i, vs)
+		}
+	}
+}
+
+func TestTreeMinMaxIteration(t *testing.T) {
+	tree := NewTree[int, int](false)
+	if _, _, ok := tree.Min(); ok {
+		t.Error("min of empty tree")
+	}
+	for _, i := range []int{5, 3, 9, 0, 12, 7, 1} {
+		tree.Insert(i, i*i)
+	}
+
+	if k, v, ok := tree.Min(); !ok || k != 0 || v != 0 {
+		t.Errorf("min expected to be 0, really %d", k)
+	}
+	if k, v, ok := tree.Max(); !ok || k != 12 || v != 144 {
+		t.Errorf("max expected to be 12, really %d", k)
+	}
+
+	expect := []int{0, 1, 3, 5, 7, 9, 12}
+	i := 0
+	for n := tree.MinNode(); n != tree.Nil; n = tree.NextNode(n) {
+		if n.Bag().Key != expect[i] {
+			t.Errorf("expect %d, not %d", expect[i], n.Bag().Key)
+		}
+		i++
+	}
+	if i != len(expect) {
+		t.Errorf("iterated %d items, expect %d", i, len(expect))
+	}
+
+	for n := tree.MaxNode(); n != tree.Nil; n = tree.PrevNode(n) {
+		i--
+		if n.Bag().Key != expect[i] {
+			t.Errorf("expect %d, not %d", expect[i], n.Bag().Key)
+		}
+	}
+}
+
+func TestTreeDupableDelete(t *testing.T) {
+	tree := NewTree[int, int](true)
+
+	items := []int{0, 0, 1, 2, 3, 4, 4, 4, 5, 6, 7, 7, 8, 9, 10}
+	for i, item := range items {
+		if err := tree.Insert(item, i); err != nil {
+			t.Errorf("unexpected error for dupable tree and key %d", item)
+		}
+	}
+
+	if vs := tree.Find(4); !slices.Equal(vs, []int{5, 6, 7}) {
+		t.Errorf("expect values in insertion order, really %v", vs)
+	}
+
+	if err := tree.Delete(40, true); err == nil {
+		t.Error("delete not exist node should give out error")
+	}
+
+	if err := tree.Delete(4, false); err != nil {
+		t.Errorf("delete unexpected error, %s", err.Error())
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify failed after delete, %s", err.Error())
+	}
+	if vs := tree.Find(4); !slices.Equal(vs, []int{6, 7}) {
+		t.Errorf("the first should be deleted, really %v", vs)
+	}
+
+	if err := tree.Delete(4, true); err != nil {
+		t.Errorf("delete unexpected error, %s", err.Error())
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify failed after delete, %s", err.Error())
+	}
+	if l := len(tree.Find(4)); l != 0 {
+		t.Error("delete all failed")
+	}
+
+	if tree.Len() != len(items)-3 {
+		t.Errorf("size error, expect %d v.s. %d", len(items)-3, tree.Len())
+	}
+}
+
+func TestTreeFunc(t *testing.T) {
+	// reversed order
+	tree := NewTreeFunc[string, struct{}](func(a, b string) int {
+		return strings.Compare(b, a)
+	}, false)
+
+	for _, w := range []string{"b", "d", "a", "c"} {
+		tree.Insert(w, struct{}{})
+	}
+
+	var got []string
+	for n := tree.MinNode(); n != tree.Nil; n = tree.NextNode(n) {
+		got = append(got, n.Bag().Key)
+	}
+	if strings.Join(got, "") != "dcba" {
+		t.Errorf("unexpected order %v", got)
+	}
+}
+
+func TestTreeVerifyOrder(t *testing.T) {
+	for _, dupable := range []bool{false, true} {
+		tree := NewTree[int, string](dupable)
+		for i := 0; i < 10; i++ {
+			tree.Insert(i, "")
+		}
+		if err := tree.Verify(); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+
+		// equal keys are only fine for dupable tree
+		tree.SelectNode(4).bag.Key = 3
+		if err := tree.Verify(); (err == nil) != dupable {
+			t.Errorf("dupable %v, verify error %v", dupable, err)
+		}
+		tree.SelectNode(4).bag.Key = 100
+		if err := tree.Verify(); err == nil {
+			t.Error("expect error for key out of order")
+		}
+	}
+}
+
+func TestTreePutSetValue(t *testing.T) {
+	for _, dupable := range []bool{false, true} {
+		tree := NewTree[int, string](dupable)
+		if !tree.Put(1, "a") || tree.Put(1, "b") || !tree.Put(2, "c") {
+			t.Error("insert of 1, update of 1 and insert of 2 expected")
+		}
+		if got := tree.Find(1); !slices.Equal(got, []string{"b"}) || tree.Len() != 2 {
+			t.Errorf("expect [b], really %v", got)
+		}
+
+		n := tree.FindNode(2)[0]
+		if err := tree.SetValue(n, "d"); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+		if n.Bag().Value != "d" || tree.FindNode(2)[0] != n {
+			t.Error("value should be set in place")
+		}
+		tree.DeleteNode(n)
+		if err := tree.SetValue(n, "e"); err == nil {
+			t.Error("expect error for node not in tree")
+		}
+		if err := tree.Verify(); err != nil {
+			t.Errorf("verify error %s", err.Error())
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package rbtree
+
+import (
+	"errors"
+)
+
+// fits reports whether bag can replace the bag of n without moving it, that
+// is bag falls strictly between neighbours of n
+func (t *RBTree) fits(n *RBNode, bag Comparable) bool {
+	if prev := t.PrevNode(n); prev != t.Nil && bag.LessEqual(prev.bag) {
+		return false
+	}
+	if next := t.NextNode(n); next != t.Nil && next.bag.LessEqual(bag) {
+		return false
+	}
+	return true
+}
+
+// UpdateKey replaces the bag of n with bag and moves n to its new place, n
+// stays a valid handle. if bag falls strictly between neighbours of n, n
+// is left in place, otherwise it is reinserted behind bags equal to it
+func (t *RBTree) UpdateKey(n *RBNode, bag Comparable) error {
+	if !t.Owns(n) {
+		return errors.New("node not in tree")
+	}
+	if t.fits(n, bag) {
+		n.bag = bag
+		return nil
+	}
+
+	if !t.dupable {
+		if m := t.seek(bag, true); m != t.Nil && m != n && m.bag.LessEqual(bag) {
+			return errors.New("duplicate key for nondupable tree")
+		}
+	}
+	t.deleteNode(n)
+	n.bag = bag
+	return t.InsertNode(n)
+}
@@ -0,0 +1,195 @@
+package rbtree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestUpdateKey(t *testing.T) {
+	tree := NewRBTree(false)
+	nodes := map[int]*RBNode{}
+	for _, item := range []int{10, 20, 30, 40, 50} {
+		n := tree.NewRBNode(MyInt(item), Red)
+		tree.InsertNode(n)
+		nodes[item] = n
+	}
+
+	// fast path, 30 to 35 keeps its place
+	root := tree.root
+	if err := tree.UpdateKey(nodes[30], MyInt(35)); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if tree.root != root || nodes[30].Bag() != MyInt(35) {
+		t.Error("node should be updated in place")
+	}
+
+	if err := tree.UpdateKey(nodes[10], MyInt(45)); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	verifyItems(t, "update key", tree, []int{20, 35, 40, 45, 50})
+	if tree.RankNode(nodes[10]) != 3 {
+		t.Errorf("handle should stay valid, rank %d", tree.RankNode(nodes[10]))
+	}
+
+	if err := tree.UpdateKey(nodes[20], MyInt(50)); err == nil {
+		t.Error("expect error for duplicate key of nondupable tree")
+	}
+	verifyItems(t, "failed update", tree, []int{20, 35, 40, 45, 50})
+
+	// stale handles
+	tree.Delete(MyInt(40), false)
+	if tree.Owns(nodes[40]) || tree.UpdateKey(nodes[40], MyInt(1)) == nil {
+		t.Error("expect error for deleted node")
+	}
+	other := NewRBTree(false)
+	other.Insert(MyInt(1))
+	other.Insert(MyInt(2))
+	for n := other.MinNode(); n != other.Nil; n = other.NextNode(n) {
+		if tree.Owns(n) || tree.UpdateKey(n, MyInt(3)) == nil {
+			t.Error("expect error for node of another tree")
+		}
+	}
+	if tree.Owns(NewRBNode(MyInt(1), Red)) || tree.Owns(tree.NewRBNode(MyInt(1), Red)) {
+		t.Error("fresh node is not owned")
+	}
+
+	// a deleted node can be inserted again, an owned one can not
+	if err := tree.InsertNode(nodes[40]); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if err := tree.InsertNode(nodes[40]); err == nil {
+		t.Error("expect error for node already in tree")
+	}
+	verifyItems(t, "reinsert", tree, []int{20, 35, 40, 45, 50})
+}
+
+func TestUpdateKeyDupable(t *testing.T) {
+	tree := NewRBTree(true)
+	a, b, c := &pair{1, "a"}, &pair{1, "b"}, &pair{2, "c"}
+	tree.Insert(a)
+	tree.Insert(b)
+	nc := tree.NewRBNode(c, Red)
+	tree.InsertNode(nc)
+
+	// moved behind bags equal to it
+	if err := tree.UpdateKey(nc, &pair{1, "c"}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	var got []string
+	for _, bag := range tree.Find(&pair{k: 1}) {
+		got = append(got, bag.(*pair).v)
+	}
+	if !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("not in insertion order: %v", got)
+	}
+}
+
+func TestUpdateKeyRandom(t *testing.T) {
+	for _, dupable := range []bool{false, true} {
+		tree := NewRBTree(dupable)
+		var nodes []*RBNode
+		for i := 0; i < 300; i++ {
+			n := tree.NewRBNode(MyInt(rand.Intn(1000)), Red)
+			if tree.InsertNode(n) == nil {
+				nodes = append(nodes, n)
+			}
+		}
+		for i := 0; i < 2000; i++ {
+			n := nodes[rand.Intn(len(nodes))]
+			bag := n.Bag().(MyInt) + MyInt(rand.Intn(21)-10)
+			if rand.Intn(5) == 0 {
+				bag = MyInt(rand.Intn(1000))
+			}
+			if err := tree.UpdateKey(n, bag); err != nil && dupable {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+		}
+		if err := tree.Verify(); err != nil {
+			t.Errorf("verify error %s", err.Error())
+		}
+		if tree.Len() != len(nodes) {
+			t.Errorf("expect len %d, really %d", len(nodes), tree.Len())
+		}
+		for _, n := range nodes {
+			if !tree.Owns(n) {
+				t.Error("handle lost")
+				break
+			}
+		}
+	}
+}
+
+func TestHandleValidation(t *testing.T) {
+	a, items := randomTree(20, 100, false)
+	b := NewRBTree(false)
+	single := NewRBTree(false)
+	single.Insert(MyInt(1))
+
+	// a node living in another tree is rejected, even the only one of it
+	for _, n := range []*RBNode{a.SelectNode(5), a.root, single.root} {
+		if err := b.InsertNode(n); err == nil {
+			t.Error("expect error for node of another tree")
+		}
+		if err := b.DeleteNode(n); err == nil {
+			t.Error("expect error for deleting node of another tree")
+		}
+	}
+	verifyItems(t, "other tree", a, items)
+	verifyItems(t, "single", single, []int{1})
+
+	n := a.SelectNode(5)
+	if err := a.DeleteNode(n); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if err := a.DeleteNode(n); err == nil {
+		t.Error("expect error for deleting node twice")
+	}
+	if err := b.InsertNode(n); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	verifyItems(t, "moved", a, slices.Delete(slices.Clone(items), 5, 6))
+	verifyItems(t, "moved", b, items[5:6])
+
+	g, h := NewTree[int, string](false), NewTree[int, string](false)
+	g.Insert(1, "a")
+	if err := h.InsertNode(g.root); err == nil {
+		t.Error("expect error for node of another generic tree")
+	}
+	if err := h.InsertNode(g.NewNode(2, "b")); err != nil || h.Len() != 1 {
+		t.Error("node made by another tree can be inserted")
+	}
+}
+
+func TestStaleHandle(t *testing.T) {
+	tree, items := randomTree(50, 100, false)
+	q := NewPriorityQueue()
+	q.Push(MyInt(1))
+
+	// links left behind point into the tree, which does not link back
+	stale := tree.NewRBNode(MyInt(1000), Black)
+	stale.p, stale.left = tree.SelectNode(10), tree.SelectNode(11)
+	under := tree.NewRBNode(MyInt(1001), Black)
+	under.p = stale
+	stale.right = under
+	for _, n := range []*RBNode{stale, under} {
+		if tree.Owns(n) || q.t.Owns(n) {
+			t.Error("stale node should not be owned")
+		}
+		if tree.DeleteNode(n) == nil || tree.UpdateKey(n, MyInt(0)) == nil || q.Remove(n) == nil {
+			t.Error("expect error for stale node")
+		}
+	}
+	verifyItems(t, "stale", tree, items)
+}
+
+func TestVerifyBagChanged(t *testing.T) {
+	tree := NewRBTree(false)
+	for i := 0; i < 10; i++ {
+		tree.Insert(MyInt(i))
+	}
+	tree.SelectNode(3).bag = MyInt(100)
+	if err := tree.Verify(); err == nil {
+		t.Error("expect error for bag changed behind the tree")
+	}
+}
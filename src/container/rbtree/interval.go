@@ -0,0 +1,171 @@
+package rbtree
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+)
+
+// Interval is a closed interval [Lo, Hi] carrying a Value
+type Interval[K, V any] struct {
+	Lo    K
+	Hi    K
+	Value V
+}
+
+// intervalBag is what an IntervalNode carries, max is kept apart from
+// Interval so results are returned as they were inserted
+type intervalBag[K, V any] struct {
+	Interval[K, V]
+	// max Hi in the subtree rooted at the node
+	max K
+}
+
+type IntervalNode[K, V any] = node[intervalBag[K, V]]
+
+// IntervalTree orders intervals by (Lo, Hi) and keeps the max endpoint of
+// every subtree up to date through rotations and fixups of the core
+type IntervalTree[K, V any] struct {
+	tree[intervalBag[K, V]]
+	cmp func(a, b K) int
+}
+
+func NewIntervalTree[K cmp.Ordered, V any]() *IntervalTree[K, V] {
+	return NewIntervalTreeFunc[K, V](cmp.Compare[K])
+}
+
+func NewIntervalTreeFunc[K, V any](cmp func(a, b K) int) *IntervalTree[K, V] {
+	t := &IntervalTree[K, V]{cmp: cmp}
+	t.init()
+	t.augment = t.updateMax
+	return t
+}
+
+func (t *IntervalTree[K, V]) updateMax(n *IntervalNode[K, V]) {
+	m := n.bag.Hi
+	if n.left != t.Nil && t.cmp(n.left.bag.max, m) > 0 {
+		m = n.left.bag.max
+	}
+	if n.right != t.Nil && t.cmp(n.right.bag.max, m) > 0 {
+		m = n.right.bag.max
+	}
+	n.bag.max = m
+}
+
+func (t *IntervalTree[K, V]) compare(a, b *Interval[K, V]) int {
+	if c := t.cmp(a.Lo, b.Lo); c != 0 {
+		return c
+	}
+	return t.cmp(a.Hi, b.Hi)
+}
+
+// identical intervals are allowed, they are kept as separated nodes
+func (t *IntervalTree[K, V]) Insert(lo, hi K, value V) error {
+	if t.cmp(lo, hi) > 0 {
+		return errors.New("interval lo greater than hi")
+	}
+
+	n := t.newNode(intervalBag[K, V]{Interval[K, V]{Lo: lo, Hi: hi, Value: value}, hi}, Red)
+	parent := t.Nil
+	p := &t.root
+	for *p != t.Nil {
+		parent = *p
+		if t.compare(&n.bag.Interval, &(*p).bag.Interval) <= 0 {
+			p = &((*p).left)
+		} else {
+			p = &((*p).right)
+		}
+	}
+	t.size += 1
+	n.p = parent
+	*p = n
+	t.updateUp(n)
+	t.insertFix(n)
+	return nil
+}
+
+// Delete removes one interval equal to [lo, hi]
+func (t *IntervalTree[K, V]) Delete(lo, hi K) error {
+	key := Interval[K, V]{Lo: lo, Hi: hi}
+	for n := t.root; n != t.Nil; {
+		c := t.compare(&key, &n.bag.Interval)
+		if c < 0 {
+			n = n.left
+		} else if c > 0 {
+			n = n.right
+		} else {
+			t.deleteNode(n)
+			return nil
+		}
+	}
+	return errors.New("interval not found")
+}
+
+// Overlapping returns intervals intersecting [a, b] ordered by (Lo, Hi).
+// subtrees whose max < a or whose Lo > b are never entered, so a visited
+// node either leads down to a reported interval or lies on the path where
+// Lo passes b, which costs O(log n + k log n) for k results, never more
+// than O(n). this is short of O(log n + k): reported intervals may sit far
+// apart at the bottom, each with its own path down, and only a structure
+// heap ordered by Hi, like a priority search tree, avoids that
+func (t *IntervalTree[K, V]) Overlapping(a, b K) (res []Interval[K, V]) {
+	if t.cmp(a, b) > 0 {
+		return
+	}
+	t.overlapping(t.root, a, b, &res)
+	return
+}
+
+// Stabbing returns intervals containing p, at the cost of Overlapping
+func (t *IntervalTree[K, V]) Stabbing(p K) []Interval[K, V] {
+	return t.Overlapping(p, p)
+}
+
+func (t *IntervalTree[K, V]) overlapping(n *IntervalNode[K, V], a, b K, res *[]Interval[K, V]) {
+	for n != t.Nil && t.cmp(n.bag.max, a) >= 0 {
+		t.overlapping(n.left, a, b, res)
+		if t.cmp(n.bag.Lo, b) > 0 {
+			// everything on the right starts even later
+			return
+		}
+		if t.cmp(n.bag.Hi, a) >= 0 {
+			*res = append(*res, n.bag.Interval)
+		}
+		n = n.right
+	}
+}
+
+// Verify checks red-black properties, order and max endpoint of every node
+func (t *IntervalTree[K, V]) Verify() error {
+	if err := t.tree.Verify(); err != nil {
+		return err
+	}
+	if t.size == 0 {
+		return nil
+	}
+	_, err := t.verifyMax(t.root)
+	return err
+}
+
+func (t *IntervalTree[K, V]) verifyMax(n *IntervalNode[K, V]) (m K, err error) {
+	m = n.bag.Hi
+	for _, c := range []*IntervalNode[K, V]{n.left, n.right} {
+		if c == t.Nil {
+			continue
+		}
+		if r := t.compare(&c.bag.Interval, &n.bag.Interval); (c == n.left && r > 0) || (c == n.right && r < 0) {
+			return m, fmt.Errorf("interval [%v, %v] out of order", c.bag.Lo, c.bag.Hi)
+		}
+		cm, err := t.verifyMax(c)
+		if err != nil {
+			return m, err
+		}
+		if t.cmp(cm, m) > 0 {
+			m = cm
+		}
+	}
+	if t.cmp(m, n.bag.max) != 0 {
+		return m, fmt.Errorf("max mismatch %v v.s. %v", n.bag.max, m)
+	}
+	return m, nil
+}
@@ -0,0 +1,115 @@
+package rbtree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+type span struct{ lo, hi int }
+
+func bruteOverlapping(spans []span, a, b int) (res []span) {
+	for _, s := range spans {
+		if s.lo <= b && s.hi >= a {
+			res = append(res, s)
+		}
+	}
+	slices.SortFunc(res, func(x, y span) int {
+		if x.lo != y.lo {
+			return x.lo - y.lo
+		}
+		return x.hi - y.hi
+	})
+	return
+}
+
+func spansOf(ivs []Interval[int, int]) (res []span) {
+	for _, iv := range ivs {
+		res = append(res, span{iv.Lo, iv.Hi})
+	}
+	return
+}
+
+func TestIntervalTree(t *testing.T) {
+	tree := NewIntervalTree[int, int]()
+	var spans []span
+	for i := 0; i < 500; i++ {
+		lo := rand.Intn(1000)
+		hi := lo + rand.Intn(50)
+		spans = append(spans, span{lo, hi})
+		if err := tree.Insert(lo, hi, i); err != nil {
+			t.Errorf("unexpected insert error %s", err.Error())
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatalf("verify error %s", err.Error())
+		}
+	}
+	// identical interval
+	spans = append(spans, spans[0])
+	tree.Insert(spans[0].lo, spans[0].hi, -1)
+
+	if err := tree.Insert(5, 4, 0); err == nil {
+		t.Error("error expected for lo > hi")
+	}
+
+	for i := 0; i < 200; i++ {
+		a := rand.Intn(1100) - 50
+		b := a + rand.Intn(30)
+		if got, expect := spansOf(tree.Overlapping(a, b)), bruteOverlapping(spans, a, b); !slices.Equal(got, expect) {
+			t.Errorf("overlapping [%d, %d] expect %v, really %v", a, b, expect, got)
+		}
+		if got, expect := spansOf(tree.Stabbing(a)), bruteOverlapping(spans, a, a); !slices.Equal(got, expect) {
+			t.Errorf("stabbing %d expect %v, really %v", a, expect, got)
+		}
+	}
+
+	for i, j := range rand.Perm(len(spans))[:300] {
+		if err := tree.Delete(spans[j].lo, spans[j].hi); err != nil {
+			t.Errorf("delete error %s", err.Error())
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatalf("verify error after %d deletes, %s", i, err.Error())
+		}
+		spans[j] = span{-100, -100}
+	}
+
+	if err := tree.Delete(-100, -100); err == nil {
+		t.Error("delete not exist interval should give out error")
+	}
+
+	live := slices.DeleteFunc(spans, func(s span) bool { return s.lo == -100 })
+	if tree.Len() != len(live) {
+		t.Errorf("size expect %d, really %d", len(live), tree.Len())
+	}
+	for i := 0; i < 200; i++ {
+		a := rand.Intn(1100) - 50
+		b := a + rand.Intn(30)
+		if got, expect := spansOf(tree.Overlapping(a, b)), bruteOverlapping(live, a, b); !slices.Equal(got, expect) {
+			t.Errorf("overlapping [%d, %d] expect %v, really %v", a, b, expect, got)
+		}
+	}
+}
+
+func TestIntervalTreeVerifyMax(t *testing.T) {
+	tree := NewIntervalTree[int, string]()
+	tree.Insert(1, 10, "a")
+	tree.Insert(2, 3, "b")
+	tree.Insert(5, 6, "c")
+
+	tree.root.bag.max = 3
+	if err := tree.Verify(); err == nil {
+		t.Error("verify should catch broken max")
+	}
+}
+
+func TestIntervalTreeResultsAsInserted(t *testing.T) {
+	tree := NewIntervalTree[int, string]()
+	tree.Insert(1, 2, "a")
+	tree.Insert(0, 100, "b")
+	tree.Insert(3, 4, "c")
+
+	expect := []Interval[int, string]{{0, 100, "b"}, {1, 2, "a"}}
+	if got := tree.Stabbing(2); !slices.Equal(got, expect) {
+		t.Errorf("expect %v, really %v", expect, got)
+	}
+}
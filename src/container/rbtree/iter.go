@@ -0,0 +1,109 @@
+package rbtree
+
+import (
+	"iter"
+)
+
+// All yields bags in order. the successor is taken before yield, so the
+// yielded bag may be deleted by the loop body, any other modification
+// during iteration is undefined, the same holds for the other iterators
+func (t *RBTree) All() iter.Seq[Comparable] {
+	return t.ascend(nil, false, nil, false)
+}
+
+func (t *RBTree) Backward() iter.Seq[Comparable] {
+	return func(yield func(Comparable) bool) {
+		for n := t.MaxNode(); n != t.Nil; {
+			prev := t.PrevNode(n)
+			if !yield(n.bag) {
+				return
+			}
+			n = prev
+		}
+	}
+}
+
+// Range yields bags between lo and hi in order, nil lo or hi means unbounded
+func (t *RBTree) Range(lo, hi Comparable, loInclusive, hiInclusive bool) iter.Seq[Comparable] {
+	return t.ascend(lo, loInclusive, hi, hiInclusive)
+}
+
+// From yields bags not less than key in order
+func (t *RBTree) From(key Comparable) iter.Seq[Comparable] {
+	return t.ascend(key, true, nil, false)
+}
+
+// ascend takes the successor before yield, so the yielded node may be
+// deleted by the loop body
+func (t *RBTree) ascend(lo Comparable, loInclusive bool, hi Comparable, hiInclusive bool) iter.Seq[Comparable] {
+	return func(yield func(Comparable) bool) {
+		n := t.MinNode()
+		if lo != nil {
+			n = t.seek(lo, loInclusive)
+		}
+		for n != t.Nil {
+			if hi != nil {
+				if hiInclusive && !n.bag.LessEqual(hi) {
+					return
+				}
+				if !hiInclusive && hi.LessEqual(n.bag) {
+					return
+				}
+			}
+			next := t.NextNode(n)
+			if !yield(n.bag) {
+				return
+			}
+			n = next
+		}
+	}
+}
+
+func (t *Tree[K, V]) All() iter.Seq2[K, V] {
+	return t.ascend(nil, false, nil, false)
+}
+
+func (t *Tree[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := t.MaxNode(); n != t.Nil; {
+			prev := t.PrevNode(n)
+			if !yield(n.bag.Key, n.bag.Value) {
+				return
+			}
+			n = prev
+		}
+	}
+}
+
+// Range yields entries with key between lo and hi in order
+func (t *Tree[K, V]) Range(lo, hi K, loInclusive, hiInclusive bool) iter.Seq2[K, V] {
+	return t.ascend(&lo, loInclusive, &hi, hiInclusive)
+}
+
+// From yields entries with key not less than key in order
+func (t *Tree[K, V]) From(key K) iter.Seq2[K, V] {
+	return t.ascend(&key, true, nil, false)
+}
+
+// nil lo or hi means unbounded
+func (t *Tree[K, V]) ascend(lo *K, loInclusive bool, hi *K, hiInclusive bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		n := t.MinNode()
+		if lo != nil {
+			n = t.seek(*lo, loInclusive)
+		}
+		for n != t.Nil {
+			if hi != nil {
+				c := t.cmp(n.bag.Key, *hi)
+				if c > 0 || (c == 0 && !hiInclusive) {
+					return
+				}
+			}
+			next := t.NextNode(n)
+			if !yield(n.bag.Key, n.bag.Value) {
+				return
+			}
+			n = next
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package rbtree
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+func collect(seq iter.Seq[Comparable]) (items []int) {
+	for bag := range seq {
+		items = append(items, int(bag.(MyInt)))
+	}
+	return
+}
+
+func TestAllBackward(t *testing.T) {
+	tree := NewRBTree(true)
+	items := []int{0, 0, 1, 2, 3, 4, 4, 4, 5, 6, 7, 7, 8, 9, 10}
+	for _, item := range items {
+		tree.Insert(MyInt(item))
+	}
+
+	if got := collect(tree.All()); !slices.Equal(got, items) {
+		t.Errorf("All not in order, %v", got)
+	}
+
+	reversed := slices.Clone(items)
+	slices.Reverse(reversed)
+	if got := collect(tree.Backward()); !slices.Equal(got, reversed) {
+		t.Errorf("Backward not in order, %v", got)
+	}
+
+	if got := collect(NewRBTree(false).All()); len(got) != 0 {
+		t.Errorf("empty tree yields %v", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tree := NewRBTree(true)
+	for _, item := range []int{0, 0, 1, 2, 3, 4, 4, 4, 5, 6, 7, 7, 8, 9, 10} {
+		tree.Insert(MyInt(item))
+	}
+
+	cases := []struct {
+		lo, hi       Comparable
+		loInc, hiInc bool
+		expect       []int
+	}{
+		{MyInt(4), MyInt(7), true, true, []int{4, 4, 4, 5, 6, 7, 7}},
+		{MyInt(4), MyInt(7), false, true, []int{5, 6, 7, 7}},
+		{MyInt(4), MyInt(7), true, false, []int{4, 4, 4, 5, 6}},
+		{MyInt(4), MyInt(7), false, false, []int{5, 6}},
+		{MyInt(-5), MyInt(1), true, true, []int{0, 0, 1}},
+		{MyInt(9), MyInt(50), false, false, []int{10}},
+		{MyInt(11), MyInt(50), true, true, nil},
+		{nil, MyInt(2), true, false, []int{0, 0, 1}},
+		{MyInt(8), nil, true, false, []int{8, 9, 10}},
+	}
+
+	for _, c := range cases {
+		if got := collect(tree.Range(c.lo, c.hi, c.loInc, c.hiInc)); !slices.Equal(got, c.expect) {
+			t.Errorf("range %v %v %v %v, expect %v, really %v", c.lo, c.hi, c.loInc, c.hiInc, c.expect, got)
+		}
+	}
+
+	if got := collect(tree.From(MyInt(7))); !slices.Equal(got, []int{7, 7, 8, 9, 10}) {
+		t.Errorf("From not match, %v", got)
+	}
+}
+
+func TestIterationBreakAndDelete(t *testing.T) {
+	tree := NewRBTree(false)
+	for i := 0; i < 100; i++ {
+		tree.Insert(MyInt(i))
+	}
+
+	n := 0
+	for range tree.All() {
+		n++
+		if n == 10 {
+			break
+		}
+	}
+	if n != 10 {
+		t.Errorf("break not honored, %d", n)
+	}
+
+	for bag := range tree.Range(MyInt(10), MyInt(20), true, false) {
+		if err := tree.Delete(bag, false); err != nil {
+			t.Errorf("delete during iteration, %s", err.Error())
+		}
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify failed after delete, %s", err.Error())
+	}
+	if tree.Len() != 90 {
+		t.Errorf("expect 90 left, really %d", tree.Len())
+	}
+}
+
+func TestTreeRange(t *testing.T) {
+	tree := NewTree[int, int](false)
+	for i := 0; i < 20; i += 2 {
+		tree.Insert(i, -i)
+	}
+
+	var keys []int
+	for k, v := range tree.Range(3, 10, true, true) {
+		if v != -k {
+			t.Errorf("value not match key %d", k)
+		}
+		keys = append(keys, k)
+	}
+	if !slices.Equal(keys, []int{4, 6, 8, 10}) {
+		t.Errorf("range not match, %v", keys)
+	}
+
+	keys = keys[:0]
+	for k := range tree.From(15) {
+		keys = append(keys, k)
+	}
+	if !slices.Equal(keys, []int{16, 18}) {
+		t.Errorf("from not match, %v", keys)
+	}
+
+	keys = keys[:0]
+	for k := range tree.Backward() {
+		keys = append(keys, k)
+		if len(keys) == 3 {
+			break
+		}
+	}
+	if !slices.Equal(keys, []int{18, 16, 14}) {
+		t.Errorf("backward not match, %v", keys)
+	}
+}
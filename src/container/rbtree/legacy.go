@@ -4,16 +4,22 @@ import (
 	"errors"
 )
 
-// caller should make sure node is in tree
 func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 	if n == nil || n == t.Nil {
 		return errors.New("rbtree: can not delete nil node")
 	}
 
-	if n.p == t.Nil && n != t.root {
+	if !t.Owns(n) {
 		return errors.New("rbtree: node to be deleted is expected to belongs to the tree")
 	}
 
+	t.plainDeleteNode(n)
+	n.left, n.right, n.p = nil, nil, nil
+	return nil
+}
+
+// caller should make sure node is in tree
+func (t *RBTree) plainDeleteNode(n *RBNode) {
 	t.size -= 1
 
 	var nextc, prevc bool
@@ -31,17 +37,18 @@ func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 			} else {
 				n.p.right = t.Nil
 			}
+			t.updateUp(n.p)
 			n.left = t.Nil
 			n.right = t.Nil
 			n.p = t.Nil
-			return nil
+			return
 		} else {
 			// delete single root
 			t.root = t.Nil
 			n.left = t.Nil
 			n.right = t.Nil
 			// n.p = nil // not needed for root
-			return nil
+			return
 		}
 	}
 
@@ -64,10 +71,11 @@ func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 				candidate.left.p = candidate
 			}
 			// keep right leaf of candidate untouched
+			t.updateUp(candidate)
 			n.left = t.Nil
 			n.right = t.Nil
 			n.p = t.Nil
-			return nil
+			return
 		}
 
 		// candidate should be left leaf of parent
@@ -81,6 +89,7 @@ func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 			t.root = candidate
 		}
 		// candidate.left == nil
+		cp := candidate.p
 		candidate.p.left = candidate.right
 		if candidate.right != t.Nil {
 			candidate.right.p = candidate.p
@@ -93,7 +102,8 @@ func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 		}
 		candidate.right = n.right
 		candidate.right.p = candidate
-		return nil
+		t.updateUp(cp)
+		return
 	}
 
 	// prevc == true
@@ -114,10 +124,11 @@ func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 			candidate.right.p = candidate
 		}
 		// keep left leaf of candidate untouched
+		t.updateUp(candidate)
 		n.left = t.Nil
 		n.right = t.Nil
 		n.p = t.Nil
-		return nil
+		return
 	}
 
 	// candidate shoudl be right leaf of parent
@@ -131,6 +142,7 @@ func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 		t.root = candidate
 	}
 	// candidate.right == nil
+	cp := candidate.p
 	candidate.p.right = candidate.left
 	if candidate.left != t.Nil {
 		candidate.left.p = candidate.p
@@ -143,7 +155,8 @@ func (t *RBTree) PlainDeleteNode(n *RBNode) error {
 	if candidate.right != t.Nil {
 		candidate.right.p = candidate
 	}
-	return nil
+	t.updateUp(cp)
+	return
 }
 
 // if not delete all, delete leftmost match
@@ -154,8 +167,7 @@ func (t *RBTree) PlainDelete(comp Comparable, all bool) error {
 
 	if nodes := t.FindNode(comp); len(nodes) > 0 {
 		if !all {
-			node := nodes[len(nodes)-1]
-			return t.PlainDeleteNode(node)
+			return t.PlainDeleteNode(nodes[0])
 		} else {
 			for _, node := range nodes {
 				if err := t.PlainDeleteNode(node); err != nil {
@@ -0,0 +1,100 @@
+package rbtree
+
+// SelectNode returns the node at 0-based position i in order, t.Nil if out of range
+func (t *tree[T]) SelectNode(i int) *node[T] {
+	if i < 0 || i >= t.size {
+		return t.Nil
+	}
+	n := t.root
+	for n != t.Nil {
+		l := n.left.count
+		if i < l {
+			n = n.left
+		} else if i > l {
+			i -= l + 1
+			n = n.right
+		} else {
+			break
+		}
+	}
+	return n
+}
+
+// RankNode returns 0-based position of n in order, n must belongs to the tree
+func (t *tree[T]) RankNode(n *node[T]) int {
+	r := n.left.count
+	for ; n.p != t.Nil; n = n.p {
+		if n == n.p.right {
+			r += n.p.left.count + 1
+		}
+	}
+	return r
+}
+
+func (t *RBTree) Select(i int) Comparable {
+	if n := t.SelectNode(i); n != t.Nil {
+		return n.bag
+	}
+	return nil
+}
+
+// Rank returns count of bags less than key, which is position of the
+// leftmost key if exists
+func (t *RBTree) Rank(key Comparable) int {
+	return t.countLess(key, false)
+}
+
+// CountRange returns count of bags in [lo, hi]
+func (t *RBTree) CountRange(lo, hi Comparable) int {
+	if c := t.countLess(hi, true) - t.countLess(lo, false); c > 0 {
+		return c
+	}
+	return 0
+}
+
+// countLess counts bags < key, or <= key if inclusive
+func (t *RBTree) countLess(key Comparable, inclusive bool) (c int) {
+	for n := t.root; n != t.Nil; {
+		var less bool
+		if inclusive {
+			less = n.bag.LessEqual(key)
+		} else {
+			less = !key.LessEqual(n.bag)
+		}
+		if less {
+			c += n.left.count + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return
+}
+
+func (t *Tree[K, V]) Select(i int) (K, V, bool) {
+	return t.entry(t.SelectNode(i))
+}
+
+func (t *Tree[K, V]) Rank(key K) int {
+	return t.countLess(key, false)
+}
+
+func (t *Tree[K, V]) CountRange(lo, hi K) int {
+	if c := t.countLess(hi, true) - t.countLess(lo, false); c > 0 {
+		return c
+	}
+	return 0
+}
+
+func (t *Tree[K, V]) countLess(key K, inclusive bool) (c int) {
+	for n := t.root; n != t.Nil; {
+		r := t.cmp(n.bag.Key, key)
+		if r < 0 || (r == 0 && inclusive) {
+			c += n.left.count + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return
+}
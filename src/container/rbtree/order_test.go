@@ -0,0 +1,115 @@
+package rbtree
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestSelectRank(t *testing.T) {
+	tree := NewRBTree(true)
+	items := []int{0, 0, 1, 2, 3, 4, 4, 4, 5, 6, 7, 7, 8, 9, 10}
+	for _, i := range rand.Perm(len(items)) {
+		tree.Insert(MyInt(items[i]))
+		if err := tree.Verify(); err != nil {
+			t.Errorf("verify error %s", err.Error())
+		}
+	}
+
+	for i, item := range items {
+		if v := tree.Select(i); v != MyInt(item) {
+			t.Errorf("select %d expect %d, really %v", i, item, v)
+		}
+	}
+	if v := tree.Select(len(items)); v != nil {
+		t.Errorf("select out of range should be nil, really %v", v)
+	}
+	if v := tree.Select(-1); v != nil {
+		t.Errorf("select out of range should be nil, really %v", v)
+	}
+
+	ranks := map[int]int{-1: 0, 0: 0, 1: 2, 4: 5, 5: 8, 7: 10, 10: 14, 11: 15}
+	for k, r := range ranks {
+		if v := tree.Rank(MyInt(k)); v != r {
+			t.Errorf("rank of %d expect %d, really %d", k, r, v)
+		}
+	}
+
+	for n := tree.MinNode(); n != tree.Nil; n = tree.NextNode(n) {
+		if r := tree.RankNode(n); tree.SelectNode(r) != n {
+			t.Errorf("select(rank(node)) != node at %d", r)
+		}
+	}
+
+	if c := tree.CountRange(MyInt(4), MyInt(7)); c != 7 {
+		t.Errorf("count range expect 7, really %d", c)
+	}
+	if c := tree.CountRange(MyInt(7), MyInt(4)); c != 0 {
+		t.Errorf("count reversed range expect 0, really %d", c)
+	}
+	if c := tree.CountRange(MyInt(-100), MyInt(100)); c != len(items) {
+		t.Errorf("count full range expect %d, really %d", len(items), c)
+	}
+}
+
+func TestCountAfterDelete(t *testing.T) {
+	for _, plain := range []bool{false, true} {
+		tree := NewRBTree(false)
+		for _, i := range rand.Perm(500) {
+			tree.Insert(MyInt(i))
+		}
+
+		for _, i := range rand.Perm(500)[:250] {
+			if plain {
+				tree.PlainDelete(MyInt(i), false)
+				// plain delete does not keep color invariant, only check counts
+				if err, _ := tree.VerifyNode(tree.root); err != nil && strings.HasPrefix(err.Error(), "count") {
+					t.Fatalf("verify error %s", err.Error())
+				}
+			} else {
+				tree.Delete(MyInt(i), false)
+				if err := tree.Verify(); err != nil {
+					t.Fatalf("verify error %s", err.Error())
+				}
+			}
+		}
+
+		if tree.root.count != 250 {
+			t.Errorf("root count expect 250, really %d", tree.root.count)
+		}
+		prev := -1
+		for i := 0; i < 250; i++ {
+			v := int(tree.Select(i).(MyInt))
+			if v <= prev {
+				t.Errorf("select not in order %d after %d", v, prev)
+			}
+			if r := tree.Rank(MyInt(v)); r != i {
+				t.Errorf("rank of %d expect %d, really %d", v, i, r)
+			}
+			prev = v
+		}
+	}
+}
+
+func TestTreeSelectRank(t *testing.T) {
+	tree := NewTree[int, string](false)
+	for _, i := range rand.Perm(100) {
+		tree.Insert(i*10, "")
+	}
+	for i := 0; i < 100; i += 7 {
+		tree.Delete(i*10, false)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+
+	for i := 0; i < tree.Len(); i++ {
+		k, _, ok := tree.Select(i)
+		if !ok || tree.Rank(k) != i {
+			t.Errorf("rank(select(%d)) != %d", i, i)
+		}
+	}
+	if c := tree.CountRange(0, 99); c != 8 {
+		t.Errorf("count range expect 8, really %d", c)
+	}
+}
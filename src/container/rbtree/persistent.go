@@ -0,0 +1,383 @@
+package rbtree
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// Persistent is an immutable red-black tree, Insert and Delete return a new
+// tree sharing untouched nodes with the old one, every version stays valid
+// and can be read concurrently without locks.
+//
+// it is kept left-leaning, which is a valid red-black tree, so recursive
+// operations only copy nodes on the search path
+type Persistent struct {
+	root    *pnode
+	size    int
+	dupable bool
+}
+
+// pnode is never modified once reachable from a Persistent
+type pnode struct {
+	color Color
+	left  *pnode
+	right *pnode
+	// count of nodes in subtree, delete works by position since equal
+	// bags of dupable tree can not tell one from another
+	count int
+	Bag   Comparable
+}
+
+func NewPersistent(dupable bool) *Persistent {
+	return &Persistent{dupable: dupable}
+}
+
+func (t *Persistent) Len() int {
+	return t.size
+}
+
+func (n *pnode) clone() *pnode {
+	c := *n
+	return &c
+}
+
+func (n *pnode) isRed() bool {
+	return n != nil && n.color == Red
+}
+
+func (n *pnode) size() int {
+	if n == nil {
+		return 0
+	}
+	return n.count
+}
+
+// the helpers below take a node created by current operation and may
+// modify it, any other node they touch is cloned first
+
+func (h *pnode) rotateLeft() *pnode {
+	x := h.right.clone()
+	h.right = x.left
+	x.left = h
+	x.color = h.color
+	h.color = Red
+	x.count = h.count
+	h.count = h.left.size() + h.right.size() + 1
+	return x
+}
+
+func (h *pnode) rotateRight() *pnode {
+	x := h.left.clone()
+	h.left = x.right
+	x.right = h
+	x.color = h.color
+	h.color = Red
+	x.count = h.count
+	h.count = h.left.size() + h.right.size() + 1
+	return x
+}
+
+func (h *pnode) flipColors() {
+	h.color = !h.color
+	h.left = h.left.clone()
+	h.left.color = !h.left.color
+	h.right = h.right.clone()
+	h.right.color = !h.right.color
+}
+
+func (h *pnode) moveRedLeft() *pnode {
+	h.flipColors()
+	if h.right.left.isRed() {
+		h.right = h.right.rotateRight()
+		h = h.rotateLeft()
+		h.flipColors()
+	}
+	return h
+}
+
+func (h *pnode) moveRedRight() *pnode {
+	h.flipColors()
+	if h.left.left.isRed() {
+		h = h.rotateRight()
+		h.flipColors()
+	}
+	return h
+}
+
+func (h *pnode) balance() *pnode {
+	if h.right.isRed() && !h.left.isRed() {
+		h = h.rotateLeft()
+	}
+	if h.left.isRed() && h.left.left.isRed() {
+		h = h.rotateRight()
+	}
+	if h.left.isRed() && h.right.isRed() {
+		h.flipColors()
+	}
+	h.count = h.left.size() + h.right.size() + 1
+	return h
+}
+
+func (t *Persistent) insert(h *pnode, comp Comparable) (*pnode, error) {
+	if h == nil {
+		return &pnode{color: Red, count: 1, Bag: comp}, nil
+	}
+
+	var err error
+	h = h.clone()
+	if t.dupable {
+		// equal keys go right, so they are kept in insertion order
+		if !h.Bag.LessEqual(comp) {
+			h.left, err = t.insert(h.left, comp)
+		} else {
+			h.right, err = t.insert(h.right, comp)
+		}
+	} else {
+		switch Compare(comp, h.Bag) {
+		case Less:
+			h.left, err = t.insert(h.left, comp)
+		case Greater:
+			h.right, err = t.insert(h.right, comp)
+		case Equal:
+			return nil, errors.New("duplicate key for nondupable tree")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return h.balance(), nil
+}
+
+// it is user's responsibility to ensure key != nil
+func (t *Persistent) Insert(comp Comparable) (*Persistent, error) {
+	root, err := t.insert(t.root, comp)
+	if err != nil {
+		return t, err
+	}
+	root.color = Black
+	return &Persistent{root: root, size: t.size + 1, dupable: t.dupable}, nil
+}
+
+func (h *pnode) deleteMin() *pnode {
+	if h.left == nil {
+		return nil
+	}
+	h = h.clone()
+	if !h.left.isRed() && !h.left.left.isRed() {
+		h = h.moveRedLeft()
+	}
+	h.left = h.left.deleteMin()
+	return h.balance()
+}
+
+// deleteAt removes the i-th node under h
+func (h *pnode) deleteAt(i int) *pnode {
+	h = h.clone()
+	if i < h.left.size() {
+		if !h.left.isRed() && !h.left.left.isRed() {
+			h = h.moveRedLeft()
+		}
+		h.left = h.left.deleteAt(i)
+	} else {
+		if h.left.isRed() {
+			h = h.rotateRight()
+		}
+		if i == h.left.size() && h.right == nil {
+			return nil
+		}
+		if !h.right.isRed() && !h.right.left.isRed() {
+			h = h.moveRedRight()
+		}
+		if l := h.left.size(); i == l {
+			m := h.right
+			for m.left != nil {
+				m = m.left
+			}
+			h.Bag = m.Bag
+			h.right = h.right.deleteMin()
+		} else {
+			h.right = h.right.deleteAt(i - l - 1)
+		}
+	}
+	return h.balance()
+}
+
+// rank returns position of the leftmost bag equal to comp, -1 if not found
+func (t *Persistent) rank(comp Comparable) int {
+	r, found := 0, -1
+	for n := t.root; n != nil; {
+		switch Compare(comp, n.Bag) {
+		case Less:
+			n = n.left
+		case Greater:
+			r += n.left.size() + 1
+			n = n.right
+		case Equal:
+			found = r + n.left.size()
+			n = n.left
+		}
+	}
+	return found
+}
+
+// Delete removes the first bag equal to comp, which is the earliest
+// inserted for dupable tree, or all of them if all is set
+func (t *Persistent) Delete(comp Comparable, all bool) (*Persistent, error) {
+	if !t.dupable && all {
+		return t, errors.New("no need to delete all for nondupable tree")
+	}
+
+	i := t.rank(comp)
+	if i < 0 {
+		return t, errors.New("key not found")
+	}
+
+	nt := t
+	for ; i >= 0; i = nt.rank(comp) {
+		root := nt.root.clone()
+		if !root.left.isRed() && !root.right.isRed() {
+			root.color = Red
+		}
+		if root = root.deleteAt(i); root != nil {
+			root.color = Black
+		}
+		nt = &Persistent{root: root, size: nt.size - 1, dupable: t.dupable}
+		if !all {
+			break
+		}
+	}
+	return nt, nil
+}
+
+// Find returns bags equal to key in order, which is insertion order for
+// dupable tree
+func (t *Persistent) Find(key Comparable) (bags []Comparable) {
+	var find func(n *pnode)
+	find = func(n *pnode) {
+		for n != nil {
+			switch Compare(key, n.Bag) {
+			case Less:
+				n = n.left
+			case Greater:
+				n = n.right
+			case Equal:
+				find(n.left)
+				bags = append(bags, n.Bag)
+				n = n.right
+				if !t.dupable {
+					return
+				}
+			}
+		}
+	}
+	find(t.root)
+	return
+}
+
+func (t *Persistent) Min() Comparable {
+	if t.root == nil {
+		return nil
+	}
+	n := t.root
+	for n.left != nil {
+		n = n.left
+	}
+	return n.Bag
+}
+
+func (t *Persistent) Max() Comparable {
+	if t.root == nil {
+		return nil
+	}
+	n := t.root
+	for n.right != nil {
+		n = n.right
+	}
+	return n.Bag
+}
+
+func (n *pnode) ascend(yield func(Comparable) bool) bool {
+	for ; n != nil; n = n.right {
+		if !n.left.ascend(yield) || !yield(n.Bag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *pnode) descend(yield func(Comparable) bool) bool {
+	for ; n != nil; n = n.left {
+		if !n.right.descend(yield) || !yield(n.Bag) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Persistent) All() iter.Seq[Comparable] {
+	return func(yield func(Comparable) bool) {
+		t.root.ascend(yield)
+	}
+}
+
+func (t *Persistent) Backward() iter.Seq[Comparable] {
+	return func(yield func(Comparable) bool) {
+		t.root.descend(yield)
+	}
+}
+
+func (t *Persistent) Verify() error {
+	if t.root == nil {
+		if t.size != 0 {
+			return fmt.Errorf("size %d of empty tree", t.size)
+		}
+		return nil
+	}
+	if t.root.color != Black {
+		return errors.New("root is red")
+	}
+	c, _, err := t.verifyNode(t.root)
+	if err != nil {
+		return err
+	}
+	if c != t.size {
+		return fmt.Errorf("count %d v.s. size %d", c, t.size)
+	}
+
+	var prev Comparable
+	for bag := range t.All() {
+		if prev != nil && !prev.LessEqual(bag) {
+			return errors.New("bags out of order")
+		}
+		prev = bag
+	}
+	return nil
+}
+
+func (t *Persistent) verifyNode(n *pnode) (count int, bh int, err error) {
+	if n == nil {
+		return 0, 0, nil
+	}
+	if n.color == Red && (n.left.isRed() || n.right.isRed()) {
+		return 0, -1, errors.New("adjacent red node")
+	}
+	cl, bhLeft, err := t.verifyNode(n.left)
+	if err != nil {
+		return 0, -1, err
+	}
+	cr, bhRight, err := t.verifyNode(n.right)
+	if err != nil {
+		return 0, -1, err
+	}
+	if bhLeft != bhRight {
+		return 0, -1, fmt.Errorf("bh diff bhLeft: %d, bhRight: %d", bhLeft, bhRight)
+	}
+	if n.count != cl+cr+1 {
+		return 0, -1, fmt.Errorf("count mismatch %d v.s. %d", n.count, cl+cr+1)
+	}
+	if n.color == Black {
+		bhLeft++
+	}
+	return cl + cr + 1, bhLeft, nil
+}
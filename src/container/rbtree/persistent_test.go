@@ -0,0 +1,206 @@
+package rbtree
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestPersistentInsertDelete(t *testing.T) {
+	tree := NewPersistent(false)
+	var versions []*Persistent
+	perm := rand.Perm(300)
+	for _, i := range perm {
+		nt, err := tree.Insert(MyInt(i))
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if err := nt.Verify(); err != nil {
+			t.Fatalf("verify error %s", err.Error())
+		}
+		versions = append(versions, tree)
+		tree = nt
+	}
+
+	if _, err := tree.Insert(MyInt(5)); err == nil {
+		t.Error("error expected for duplicate key of nondupable tree")
+	}
+
+	// old versions are untouched
+	for i, v := range versions {
+		if v.Len() != i {
+			t.Errorf("version %d has size %d", i, v.Len())
+		}
+		if err := v.Verify(); err != nil {
+			t.Errorf("verify version %d error %s", i, err.Error())
+		}
+		expect := slices.Sorted(slices.Values(perm[:i]))
+		if got := collect(v.All()); !slices.Equal(got, expect) {
+			t.Errorf("version %d expect %v, really %v", i, expect, got)
+		}
+	}
+
+	full := tree
+	for _, i := range rand.Perm(300) {
+		nt, err := tree.Delete(MyInt(i), false)
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if err := nt.Verify(); err != nil {
+			t.Fatalf("verify error after delete %d, %s", i, err.Error())
+		}
+		if vs := nt.Find(MyInt(i)); len(vs) != 0 {
+			t.Errorf("%d should be deleted", i)
+		}
+		tree = nt
+	}
+	if tree.Len() != 0 {
+		t.Errorf("tree should be empty, %d", tree.Len())
+	}
+	if _, err := tree.Delete(MyInt(1), false); err == nil {
+		t.Error("delete not exist node should give out error")
+	}
+
+	if full.Len() != 300 || full.Min() != MyInt(0) || full.Max() != MyInt(299) {
+		t.Error("full version changed by delete")
+	}
+	if err := full.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+}
+
+func TestPersistentDupable(t *testing.T) {
+	tree := NewPersistent(true)
+	items := []int{0, 0, 1, 2, 3, 4, 4, 4, 5, 6, 7, 7, 8, 9, 10}
+	for _, i := range rand.Perm(len(items)) {
+		tree, _ = tree.Insert(MyInt(items[i]))
+	}
+
+	if got := collect(tree.All()); !slices.Equal(got, items) {
+		t.Errorf("not in order %v", got)
+	}
+	reversed := slices.Clone(items)
+	slices.Reverse(reversed)
+	if got := collect(tree.Backward()); !slices.Equal(got, reversed) {
+		t.Errorf("backward not in order %v", got)
+	}
+
+	if vs := tree.Find(MyInt(4)); len(vs) != 3 {
+		t.Errorf("expect to find 3 items, really %d", len(vs))
+	}
+
+	one, err := tree.Delete(MyInt(4), false)
+	if err != nil || len(one.Find(MyInt(4))) != 2 || one.Verify() != nil {
+		t.Error("delete one of 4 failed")
+	}
+	all, err := tree.Delete(MyInt(4), true)
+	if err != nil || len(all.Find(MyInt(4))) != 0 || all.Verify() != nil || all.Len() != len(items)-3 {
+		t.Error("delete all of 4 failed")
+	}
+	if len(tree.Find(MyInt(4))) != 3 {
+		t.Error("original version changed by delete")
+	}
+
+	for i := 0; i < 50; i++ {
+		for _, v := range []int{7, 0, 4, 10} {
+			tree, _ = tree.Insert(MyInt(v))
+		}
+		if i%3 == 0 {
+			tree, _ = tree.Delete(MyInt(7), true)
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatalf("verify error %s", err.Error())
+		}
+	}
+}
+
+func TestPersistentInsertionOrder(t *testing.T) {
+	tree := NewPersistent(true)
+	var items []*pair
+	for i := 0; i < 30; i++ {
+		items = append(items, &pair{i % 3, string(rune('a' + i))})
+		tree, _ = tree.Insert(items[i])
+	}
+	var expect []Comparable
+	for i := 1; i < 30; i += 3 {
+		expect = append(expect, items[i])
+	}
+	if got := tree.Find(&pair{k: 1}); !slices.Equal(got, expect) {
+		t.Errorf("not in insertion order: %v", got)
+	}
+
+	// the earliest goes first, like RBTree
+	tree, _ = tree.Delete(&pair{k: 1}, false)
+	if got := tree.Find(&pair{k: 1}); !slices.Equal(got, expect[1:]) {
+		t.Errorf("earliest not deleted: %v", got)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+}
+
+func TestPersistentConcurrentRead(t *testing.T) {
+	tree := NewPersistent(false)
+	for i := 0; i < 1000; i++ {
+		tree, _ = tree.Insert(MyInt(i))
+	}
+	snapshot := tree
+
+	var wg sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if n := len(collect(snapshot.All())); n != 1000 {
+					t.Errorf("snapshot changed, %d", n)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i += 2 {
+		tree, _ = tree.Delete(MyInt(i), false)
+		tree, _ = tree.Insert(MyInt(i + 5000))
+	}
+	wg.Wait()
+
+	if err := snapshot.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+}
+
+func TestPersistentRandomDupable(t *testing.T) {
+	tree := NewPersistent(true)
+	counts := map[int]int{}
+	for i := 0; i < 3000; i++ {
+		v := rand.Intn(20)
+		if rand.Intn(3) == 0 {
+			nt, err := tree.Delete(MyInt(v), rand.Intn(5) == 0)
+			if (err == nil) != (counts[v] > 0) {
+				t.Fatalf("delete %d error %v, count %d", v, err, counts[v])
+			}
+			if err == nil {
+				counts[v] -= tree.Len() - nt.Len()
+			}
+			tree = nt
+		} else {
+			tree, _ = tree.Insert(MyInt(v))
+			counts[v]++
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatalf("verify error %s", err.Error())
+		}
+	}
+
+	for v, c := range counts {
+		if n := len(tree.Find(MyInt(v))); n != c {
+			t.Errorf("count of %d expect %d, really %d", v, c, n)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package rbtree
+
+import (
+	"errors"
+)
+
+// PriorityQueue is a double-ended priority queue on a dupable RBTree, bags
+// are priorities, equal ones pop in push order from the min side.
+//
+// min and max nodes are cached, rotations and deletion of other nodes never
+// move a node out of its place in order, so the cache only changes when
+// the min or max itself is pushed over or popped, then the new one is its
+// neighbour, which takes O(1) amortized instead of a descent from root
+type PriorityQueue struct {
+	t   *RBTree
+	min *RBNode
+	max *RBNode
+}
+
+func NewPriorityQueue() *PriorityQueue {
+	t := NewRBTree(true)
+	return &PriorityQueue{t: t, min: t.Nil, max: t.Nil}
+}
+
+func (q *PriorityQueue) Len() int {
+	return q.t.Len()
+}
+
+// Push adds item and returns the handle of it for UpdatePriority and
+// Remove
+func (q *PriorityQueue) Push(item Comparable) *RBNode {
+	n := q.t.NewRBNode(item, Red)
+	q.insert(n)
+	return n
+}
+
+func (q *PriorityQueue) insert(n *RBNode) {
+	q.t.InsertNode(n) // never fails for dupable tree
+	if q.min == q.t.Nil || !q.min.bag.LessEqual(n.bag) {
+		q.min = n
+	}
+	// equal one goes right, so it is the new max
+	if q.max == q.t.Nil || q.max.bag.LessEqual(n.bag) {
+		q.max = n
+	}
+}
+
+func (q *PriorityQueue) remove(n *RBNode) {
+	if n == q.min {
+		q.min = q.t.NextNode(n)
+	}
+	if n == q.max {
+		q.max = q.t.PrevNode(n)
+	}
+	q.t.deleteNode(n)
+}
+
+// PeekMin returns the min item, nil if empty
+func (q *PriorityQueue) PeekMin() Comparable {
+	return q.min.bag
+}
+
+// PeekMax returns the max item, nil if empty
+func (q *PriorityQueue) PeekMax() Comparable {
+	return q.max.bag
+}
+
+// PopMin removes and returns the min item, nil if empty
+func (q *PriorityQueue) PopMin() Comparable {
+	n := q.min
+	if n == q.t.Nil {
+		return nil
+	}
+	q.remove(n)
+	return n.bag
+}
+
+// PopMax removes and returns the max item, nil if empty
+func (q *PriorityQueue) PopMax() Comparable {
+	n := q.max
+	if n == q.t.Nil {
+		return nil
+	}
+	q.remove(n)
+	return n.bag
+}
+
+// Remove deletes item of handle h
+func (q *PriorityQueue) Remove(h *RBNode) error {
+	if !q.t.Owns(h) {
+		return errors.New("handle not in queue")
+	}
+	q.remove(h)
+	return nil
+}
+
+// UpdatePriority replaces item of handle h and moves it accordingly like
+// RBTree.UpdateKey, h stays valid
+func (q *PriorityQueue) UpdatePriority(h *RBNode, item Comparable) error {
+	if !q.t.Owns(h) {
+		return errors.New("handle not in queue")
+	}
+	// cache stays valid if h is not moved
+	if q.t.fits(h, item) {
+		h.bag = item
+		return nil
+	}
+	q.remove(h)
+	h.bag = item
+	q.insert(h)
+	return nil
+}
+
+// Verify checks the tree and cached min and max
+func (q *PriorityQueue) Verify() error {
+	if err := q.t.Verify(); err != nil {
+		return err
+	}
+	if q.min != q.t.MinNode() || q.max != q.t.MaxNode() {
+		return errors.New("cached min or max is stale")
+	}
+	return nil
+}
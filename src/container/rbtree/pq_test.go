@@ -0,0 +1,126 @@
+package rbtree
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := NewPriorityQueue()
+	if q.PeekMin() != nil || q.PopMax() != nil {
+		t.Error("empty queue expected")
+	}
+
+	a, b, c := &pair{2, "a"}, &pair{1, "b"}, &pair{2, "c"}
+	ha := q.Push(a)
+	q.Push(b)
+	hc := q.Push(c)
+	if q.PeekMin() != b || q.PeekMax() != c {
+		t.Error("min b and max c expected")
+	}
+
+	// c is behind a of equal priority, until it is updated to the front
+	if err := q.UpdatePriority(hc, &pair{0, "c"}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if q.PeekMin().(*pair).v != "c" || q.PeekMax() != a {
+		t.Error("min c and max a expected")
+	}
+	if err := q.Remove(ha); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if err := q.Remove(ha); err == nil {
+		t.Error("expect error for removed handle")
+	}
+	if err := q.UpdatePriority(NewPriorityQueue().Push(a), a); err == nil {
+		t.Error("expect error for handle of another queue")
+	}
+	if err := q.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+
+	if q.PopMax() != b || q.PopMin().(*pair).v != "c" || q.Len() != 0 {
+		t.Error("pop b then c expected")
+	}
+	if err := q.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+}
+
+func TestPriorityQueueFIFO(t *testing.T) {
+	q := NewPriorityQueue()
+	var items []*pair
+	for i := 0; i < 30; i++ {
+		items = append(items, &pair{i % 3, string(rune('a' + i))})
+		q.Push(items[i])
+	}
+	for k := 0; k < 3; k++ {
+		for i := k; i < 30; i += 3 {
+			if got := q.PopMin(); got != items[i] {
+				t.Errorf("expect %v, really %v", items[i], got)
+			}
+		}
+	}
+}
+
+func TestPriorityQueueRandom(t *testing.T) {
+	q := NewPriorityQueue()
+	handles := map[*RBNode]int{}
+	for i := 0; i < 3000; i++ {
+		switch rand.Intn(6) {
+		case 0, 1:
+			if rand.Intn(2) == 0 {
+				q.PopMin()
+			} else {
+				q.PopMax()
+			}
+			// the popped handle is no longer owned
+			for h := range handles {
+				if !q.t.Owns(h) {
+					delete(handles, h)
+				}
+			}
+		case 2:
+			for h := range handles {
+				v := rand.Intn(100)
+				if err := q.UpdatePriority(h, MyInt(v)); err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				handles[h] = v
+				break
+			}
+		case 3:
+			for h := range handles {
+				if err := q.Remove(h); err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				delete(handles, h)
+				break
+			}
+		default:
+			v := rand.Intn(100)
+			handles[q.Push(MyInt(v))] = v
+		}
+
+		if err := q.Verify(); err != nil {
+			t.Fatalf("verify error %s", err.Error())
+		}
+		if q.Len() != len(handles) {
+			t.Fatalf("expect len %d, really %d", len(handles), q.Len())
+		}
+	}
+
+	var expect []int
+	for _, v := range handles {
+		expect = append(expect, v)
+	}
+	slices.Sort(expect)
+	var got []int
+	for q.Len() > 0 {
+		got = append(got, int(q.PopMin().(MyInt)))
+	}
+	if !slices.Equal(got, expect) {
+		t.Errorf("expect %v, really %v", expect, got)
+	}
+}
@@ -35,35 +35,116 @@ func Compare(a Comparable, b Comparable) Relation {
 	}
 }
 
-type RBTree struct {
-	root    *RBNode
-	dupable bool
-	size    int
-	Nil     *RBNode
+// tree is the balancing core shared by RBTree and Tree, it knows nothing
+// about ordering, callers do the descent and hand nodes to insertFix/deleteNode
+type tree[T any] struct {
+	root *node[T]
+	size int
+	Nil  *node[T]
+	// augment, if set, recomputes extra fields a wrapper keeps in bag,
+	// it is called whenever children of a node change
+	augment func(n *node[T])
 }
 
-type RBNode struct {
+type node[T any] struct {
 	color Color
-	left  *RBNode
-	right *RBNode
-	p     *RBNode
-	Bag   Comparable
+	left  *node[T]
+	right *node[T]
+	p     *node[T]
+	// count of nodes in subtree rooted here, sentinel is always 0
+	count int
+	// bag decides where the node is, so it is read only from outside
+	bag T
+}
+
+// Bag returns what n carries, use RBTree.UpdateKey to change it, or
+// Tree.SetValue for value of an Entry
+func (n *node[T]) Bag() T {
+	return n.bag
+}
+
+type RBTree struct {
+	tree[Comparable]
+	dupable bool
 }
 
+type RBNode = node[Comparable]
+
+// rbNil is the sentinel of every RBTree, the tree code never writes it, so
+// subtrees move between trees without touching their leaves
+var rbNil = &RBNode{color: Black}
+
 func NewRBNode(comp Comparable, color Color) *RBNode {
-	return &RBNode{Bag: comp, color: color}
+	return &RBNode{bag: comp, color: color}
 }
 
 func NewRBTree(dupable bool) *RBTree {
-	sentinel := NewRBNode(nil, Black)
-	return &RBTree{dupable: dupable, Nil: sentinel, root: sentinel}
+	t := &RBTree{dupable: dupable}
+	t.reset()
+	return t
+}
+
+func (t *RBTree) reset() {
+	t.Nil, t.root, t.size = rbNil, rbNil, 0
 }
 
 func (t *RBTree) NewRBNode(comp Comparable, color Color) *RBNode {
-	return &RBNode{Bag: comp, color: color, left: t.Nil, right: t.Nil, p: t.Nil}
+	return t.newNode(comp, color)
+}
+
+func (t *tree[T]) init() {
+	sentinel := &node[T]{color: Black}
+	t.Nil = sentinel
+	t.root = sentinel
+}
+
+func (t *tree[T]) newNode(bag T, color Color) *node[T] {
+	return &node[T]{bag: bag, color: color, left: t.Nil, right: t.Nil, count: 1}
+}
+
+// detached reports whether n is in no tree, that is a node just made by
+// NewRBNode or newNode, or one removed from a tree. every node in a tree
+// has a parent, which is the sentinel for root, so p tells it alone
+func detached[T any](n *node[T]) bool {
+	return n.p == nil
 }
 
-func (t *RBTree) rotateLeft(x *RBNode) error {
+// update recomputes cached fields of n from its children
+func (t *tree[T]) update(n *node[T]) {
+	n.count = n.left.count + n.right.count + 1
+	if t.augment != nil {
+		t.augment(n)
+	}
+}
+
+func (t *tree[T]) updateUp(n *node[T]) {
+	for ; n != t.Nil; n = n.p {
+		t.update(n)
+	}
+}
+
+// Owns reports whether n is a node of t, it is O(log n). parents are
+// followed up to root, each must link back to the node below, so a stale
+// handle is never owned even if its links were left behind
+func (t *tree[T]) Owns(n *node[T]) bool {
+	if n == nil || n == t.Nil {
+		return false
+	}
+	for ; n.p != t.Nil; n = n.p {
+		// removed node has nil links, sentinel of another tree has nil
+		// children
+		if n.p == nil || (n.p.left != n && n.p.right != n) {
+			return false
+		}
+	}
+	return n == t.root
+}
+
+func (t *tree[T]) Len() int {
+	return t.size
+}
+
+func (t *tree[T]) rotateLeft(x *node[T]) error {
 	if x.right == t.Nil {
 		return errors.New("rotate left require right child not nil")
 	}
@@ -89,10 +170,12 @@ func (t *RBTree) rotateLeft(x *RBNode) error {
 	}
 
 	x.p = y
+	t.update(x)
+	t.update(y)
 	return nil
 }
 
-func (t *RBTree) rotateRight(y *RBNode) error {
+func (t *tree[T]) rotateRight(y *node[T]) error {
 	if y.left == t.Nil {
 		return errors.New("rotate right require left child not nil")
 	}
@@ -118,10 +201,12 @@ func (t *RBTree) rotateRight(y *RBNode) error {
 	}
 
 	y.p = x
+	t.update(y)
+	t.update(x)
 	return nil
 }
 
-func (t *RBTree) insertFix(z *RBNode) {
+func (t *tree[T]) insertFix(z *node[T]) {
 	for z.p.color == Red {
 		// enter the for loop imply z is not root and z.p.p != t.Nil, because
 		// if z is root (z.p == t.Nil), remember that sentinel(z.p) is black,
@@ -166,15 +251,20 @@ func (t *RBTree) insertFix(z *RBNode) {
 	t.root.color = Black
 }
 
+// InsertNode inserts n, which may be a node removed before, its links and
+// color are reset. n must not be in any tree
 func (t *RBTree) InsertNode(n *RBNode) error {
+	if n == nil || !detached(n) {
+		return errors.New("node already in a tree")
+	}
 	parent := t.Nil
-	t.size += 1
 	// p is **RBNode
 	p := &t.root
 	for *p != t.Nil {
 		parent = *p
 		if t.dupable {
-			if n.Bag.LessEqual((*p).Bag) {
+			// equal keys go right, so they are kept in insertion order
+			if !(*p).bag.LessEqual(n.bag) {
 				// we can ignore a "*()" for golang treat "." as "->"
 				// just an illustration
 				// p = &((*(*p)).left)
@@ -184,7 +274,7 @@ func (t *RBTree) InsertNode(n *RBNode) error {
 				p = &((*(*p)).right)
 			}
 		} else {
-			switch Compare(n.Bag, (*p).Bag) {
+			switch Compare(n.bag, (*p).bag) {
 			case Less:
 				p = &((*p).left)
 			case Greater:
@@ -194,10 +284,13 @@ func (t *RBTree) InsertNode(n *RBNode) error {
 			}
 		}
 	}
+	t.size += 1
 	n.p = parent
 	*p = n
-	// n.left = t.Nil
-	// n.right = t.Nil
+	n.left = t.Nil
+	n.right = t.Nil
+	n.color = Red
+	t.updateUp(n)
 	t.insertFix(n)
 	return nil
 }
@@ -209,7 +302,7 @@ func (t *RBTree) Insert(comp Comparable) error {
 }
 
 // replace u with v, only update v and parent(maybe root) relationship
-func (t *RBTree) transplantUp(u *RBNode, v *RBNode) {
+func (t *tree[T]) transplantUp(u *node[T], v *node[T]) {
 	if u.p == t.Nil {
 		t.root = v
 	} else if u == u.p.left {
@@ -217,31 +310,34 @@ func (t *RBTree) transplantUp(u *RBNode, v *RBNode) {
 	} else {
 		u.p.right = v
 	}
-	v.p = u.p
+	if v != t.Nil {
+		v.p = u.p
+	}
 }
 
-func (t *RBTree) deleteFix(x *RBNode) {
-	// x may be sentinel, but x.p have been set properly
+// deleteFix takes parent of x apart, since x may be sentinel, which is
+// never written so it can be shared
+func (t *tree[T]) deleteFix(x, xp *node[T]) {
 	for x != t.root && x.color == Black {
-		if x == x.p.left {
+		if x == xp.left {
 			// x points to a extra black node, so sibling of it can not be sentinel
-			// otherwise, the tree violate bhHeight(x.p) equal rule
-			w := x.p.right
+			// otherwise, the tree violate bhHeight(xp) equal rule
+			w := xp.right
 			if w.color == Red {
 				// if w is red, remember x is double black
-				// due to reason alike bhHeight(x.p)
+				// due to reason alike bhHeight(xp)
 				// all children of w should exist and be black
 				w.color = Black
-				x.p.color = Red
-				t.rotateLeft(x.p)
-				w = x.p.right
+				xp.color = Red
+				t.rotateLeft(xp)
+				w = xp.right
 			}
 			// when arrived here, w(sibling of x) is black
 			if w.left.color == Black && w.right.color == Black {
 				// move x upward and make w red, no bhHeight change totally
 				// cover the case that children of w both are sentinel
 				w.color = Red
-				x = x.p
+				x, xp = xp, xp.p
 			} else {
 				// children of w can't be both sentinel otherwise they are all black
 				// at least one of them are red
@@ -251,37 +347,37 @@ func (t *RBTree) deleteFix(x *RBNode) {
 					w.left.color = Black
 					w.color = Red
 					t.rotateRight(w)
-					w = x.p.right
+					w = xp.right
 					// now w is black and right child of it is red
 				}
-				w.color = x.p.color
-				x.p.color = Black
+				w.color = xp.color
+				xp.color = Black
 				w.right.color = Black
-				t.rotateLeft(x.p)
+				t.rotateLeft(xp)
 				x = t.root
 			}
-		} else { // x == x.p.right
-			w := x.p.left
+		} else { // x == xp.right
+			w := xp.left
 			if w.color == Red {
 				w.color = Black
-				x.p.color = Red
-				t.rotateRight(x.p)
-				w = x.p.left
+				xp.color = Red
+				t.rotateRight(xp)
+				w = xp.left
 			}
 			if w.left.color == Black && w.right.color == Black {
 				w.color = Red
-				x = x.p
+				x, xp = xp, xp.p
 			} else {
 				if w.left.color == Black {
 					w.right.color = Black
 					w.color = Red
 					t.rotateLeft(w)
-					w = x.p.left
+					w = xp.left
 				}
-				w.color = x.p.color
-				x.p.color = Black
+				w.color = xp.color
+				xp.color = Black
 				w.left.color = Black
-				t.rotateRight(x.p)
+				t.rotateRight(xp)
 				x = t.root
 			}
 		}
@@ -289,19 +385,31 @@ func (t *RBTree) deleteFix(x *RBNode) {
 	// case 1: x points to a red-and-black node, make it black
 	// case 2: x points to root, just drop the extra black
 	// case 3: suitable rotations and recolorings done, exit loop
-	x.color = Black
+	if x != t.Nil {
+		x.color = Black
+	}
+}
+
+// DeleteNode removes n, which must be a node of t
+func (t *tree[T]) DeleteNode(n *node[T]) error {
+	if !t.Owns(n) {
+		return errors.New("node not in tree")
+	}
+	t.deleteNode(n)
+	return nil
 }
 
-func (t *RBTree) DeleteNode(z *RBNode) {
+func (t *tree[T]) deleteNode(z *node[T]) {
 	t.size -= 1
-	var x *RBNode
+	// x takes the place of y, xp is parent of x after all
+	var x, xp *node[T]
 	y := z
 	yOrigColor := y.color
 	if z.left == t.Nil {
-		x = z.right
+		x, xp = z.right, z.p
 		t.transplantUp(z, z.right)
 	} else if z.right == t.Nil {
-		x = z.left
+		x, xp = z.left, z.p
 		t.transplantUp(z, z.left)
 	} else {
 		y = t.nextChild(z) // y could not be t.Nil
@@ -310,11 +418,10 @@ func (t *RBTree) DeleteNode(z *RBNode) {
 		if y.p == z {
 			// y is directly child of z, not need to pick and put
 			// just replace z with y is OK
-			//if x == t.Nil {
-			x.p = y // TODO, only need when x is sentinel
-			//}
+			xp = y
 		} else {
 			// y need to be picked and put in the position of z
+			xp = y.p
 			t.transplantUp(y, y.right) // y.right maybe t.Nil
 			// now y is detached from parent
 			// deal with right child of z
@@ -328,13 +435,18 @@ func (t *RBTree) DeleteNode(z *RBNode) {
 		y.left.p = y
 		y.color = z.color
 	}
+	// xp is the lowest node whose subtree changed
+	t.updateUp(xp)
 	if yOrigColor == Black {
 		// we've removed a black node
 		// x point to where the original black node reside
-		t.deleteFix(x)
+		t.deleteFix(x, xp)
 	}
+	z.left, z.right, z.p = nil, nil, nil
 }
 
+// Delete removes the first bag equal to comp, which is the earliest
+// inserted for dupable tree, or all of them if all is set
 func (t *RBTree) Delete(comp Comparable, all bool) error {
 	if !t.dupable && all {
 		return errors.New("no need to delete all for nondupable tree")
@@ -342,12 +454,11 @@ func (t *RBTree) Delete(comp Comparable, all bool) error {
 
 	if nodes := t.FindNode(comp); len(nodes) > 0 {
 		if !all {
-			node := nodes[len(nodes)-1]
-			t.DeleteNode(node)
+			t.deleteNode(nodes[0])
 			return nil
 		} else {
 			for _, node := range nodes {
-				t.DeleteNode(node)
+				t.deleteNode(node)
 			}
 			return nil
 		}
@@ -356,51 +467,48 @@ func (t *RBTree) Delete(comp Comparable, all bool) error {
 	}
 }
 
-func (t *RBTree) Find(key Comparable) (bags []Comparable) {
-	nodes := t.FindNode(key)
+// DeleteFirst removes the earliest inserted bag equal to comp
+func (t *RBTree) DeleteFirst(comp Comparable) error {
+	return t.Delete(comp, false)
+}
 
-	for _, n := range nodes {
-		bags = append(bags, n.Bag)
+// DeleteLast removes the latest inserted bag equal to comp
+func (t *RBTree) DeleteLast(comp Comparable) error {
+	n := t.seekBack(comp, true)
+	if n == t.Nil || !comp.LessEqual(n.bag) {
+		return errors.New("key not found")
 	}
-	return
+	t.deleteNode(n)
+	return nil
 }
 
-// for dupable tree, random sequence
-func (t *RBTree) FindNode(key Comparable) (nodes []*RBNode) {
-	if t.size == 0 {
-		return
-	}
-	n := t.root
-LOOP:
-	for n != t.Nil {
-		switch Compare(key, n.Bag) {
-		case Less:
-			n = n.left
-		case Greater:
-			n = n.right
-		case Equal:
-			nodes = append(nodes, n)
-			break LOOP
+// DeleteValue removes the earliest inserted bag equal to comp which is bag
+// itself, that is bag == node.bag, so bag must be of a comparable type
+func (t *RBTree) DeleteValue(bag Comparable) error {
+	for _, n := range t.FindNode(bag) {
+		if n.bag == bag {
+			t.deleteNode(n)
+			return nil
 		}
 	}
-	if !t.dupable || n == t.Nil {
-		return
-	}
+	return errors.New("value not found")
+}
 
-	next := n
-	for next = t.NextNode(next); next != t.Nil; next = t.NextNode(next) {
-		if Compare(key, next.Bag) == Equal {
-			nodes = append(nodes, next)
-		} else {
-			break
-		}
+func (t *RBTree) Find(key Comparable) (bags []Comparable) {
+	nodes := t.FindNode(key)
+
+	for _, n := range nodes {
+		bags = append(bags, n.bag)
 	}
+	return
+}
 
-	prev := n
-	for prev = t.PrevNode(prev); prev != t.Nil; prev = t.PrevNode(prev) {
-		if Compare(key, prev.Bag) == Equal {
-			nodes = append(nodes, prev)
-		} else {
+// FindNode returns nodes equal to key in order, which is insertion order
+// for dupable tree
+func (t *RBTree) FindNode(key Comparable) (nodes []*RBNode) {
+	for n := t.seek(key, true); n != t.Nil && n.bag.LessEqual(key); n = t.NextNode(n) {
+		nodes = append(nodes, n)
+		if !t.dupable {
 			break
 		}
 	}
@@ -415,10 +523,10 @@ func (t *RBTree) Min() Comparable {
 	for n.left != t.Nil {
 		n = n.left
 	}
-	return n.Bag
+	return n.bag
 }
 
-func (t *RBTree) MinNode() *RBNode {
+func (t *tree[T]) MinNode() *node[T] {
 	if t.size == 0 {
 		return t.Nil
 	}
@@ -437,10 +545,10 @@ func (t *RBTree) Max() Comparable {
 	for n.right != t.Nil {
 		n = n.right
 	}
-	return n.Bag
+	return n.bag
 }
 
-func (t *RBTree) MaxNode() *RBNode {
+func (t *tree[T]) MaxNode() *node[T] {
 	if t.size == 0 {
 		return t.Nil
 	}
@@ -451,7 +559,7 @@ func (t *RBTree) MaxNode() *RBNode {
 	return n
 }
 
-func (t *RBTree) prevChild(n *RBNode) *RBNode {
+func (t *tree[T]) prevChild(n *node[T]) *node[T] {
 	if n.left != t.Nil {
 		n = n.left
 		for n.right != t.Nil {
@@ -462,7 +570,7 @@ func (t *RBTree) prevChild(n *RBNode) *RBNode {
 	return t.Nil
 }
 
-func (t *RBTree) prevParent(n *RBNode) *RBNode {
+func (t *tree[T]) prevParent(n *node[T]) *node[T] {
 	for n.p != t.Nil {
 		if n.p.right == n {
 			return n.p
@@ -472,7 +580,7 @@ func (t *RBTree) prevParent(n *RBNode) *RBNode {
 	return t.Nil
 }
 
-func (t *RBTree) PrevNode(n *RBNode) *RBNode {
+func (t *tree[T]) PrevNode(n *node[T]) *node[T] {
 	if prev := t.prevChild(n); prev != t.Nil {
 		return prev
 	} else if prev := t.prevParent(n); prev != t.Nil {
@@ -482,7 +590,7 @@ func (t *RBTree) PrevNode(n *RBNode) *RBNode {
 	return t.Nil
 }
 
-func (t *RBTree) nextChild(n *RBNode) *RBNode {
+func (t *tree[T]) nextChild(n *node[T]) *node[T] {
 	if n.right != t.Nil {
 		n = n.right
 		for n.left != t.Nil {
@@ -493,7 +601,7 @@ func (t *RBTree) nextChild(n *RBNode) *RBNode {
 	return t.Nil
 }
 
-func (t *RBTree) nextParent(n *RBNode) *RBNode {
+func (t *tree[T]) nextParent(n *node[T]) *node[T] {
 	for n.p != t.Nil {
 		if n.p.left == n {
 			return n.p
@@ -503,7 +611,7 @@ func (t *RBTree) nextParent(n *RBNode) *RBNode {
 	return t.Nil
 }
 
-func (t *RBTree) NextNode(n *RBNode) *RBNode {
+func (t *tree[T]) NextNode(n *node[T]) *node[T] {
 	if next := t.nextChild(n); next != t.Nil {
 		return next
 	} else if next := t.nextParent(n); next != t.Nil {
@@ -513,18 +621,36 @@ func (t *RBTree) NextNode(n *RBNode) *RBNode {
 	return t.Nil
 }
 
-func (t *RBTree) Verify() error {
+func (t *tree[T]) Verify() error {
 	if t.size > 0 {
 		if t.root.color != Black {
 			return errors.New("root is red")
 		}
+		if t.root.count != t.size {
+			return fmt.Errorf("root count %d v.s. size %d", t.root.count, t.size)
+		}
 		err, _ := t.VerifyNode(t.root)
 		return err
 	}
 	return nil
 }
 
-func (t *RBTree) VerifyNode(n *RBNode) (err error, bh int) {
+// Verify also checks bags are in order
+func (t *RBTree) Verify() error {
+	if err := t.tree.Verify(); err != nil {
+		return err
+	}
+	for n := t.MinNode(); n != t.Nil; n = t.NextNode(n) {
+		if prev := t.PrevNode(n); prev != t.Nil {
+			if !prev.bag.LessEqual(n.bag) || (!t.dupable && n.bag.LessEqual(prev.bag)) {
+				return fmt.Errorf("bags out of order at %d", t.RankNode(n))
+			}
+		}
+	}
+	return nil
+}
+
+func (t *tree[T]) VerifyNode(n *node[T]) (err error, bh int) {
 	if n.color == Red {
 		if n.left.color == Red || n.right.color == Red {
 			return errors.New("adjacent red node"), -1
@@ -544,6 +670,9 @@ func (t *RBTree) VerifyNode(n *RBNode) (err error, bh int) {
 			return err, -1
 		}
 	}
+	if n.count != n.left.count+n.right.count+1 {
+		return fmt.Errorf("count mismatch %d v.s. %d", n.count, n.left.count+n.right.count+1), -1
+	}
 	if bhLeft == bhRight {
 		bh = bhLeft
 		if n.color == Black {
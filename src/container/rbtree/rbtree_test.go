@@ -115,8 +115,8 @@ func TestInteration(t *testing.T) {
 			t.Error("too many, should be at most 12")
 		}
 
-		if Compare(i, cur.Bag) != Equal {
-			t.Errorf("expect %d, not %d", i, cur.Bag)
+		if Compare(i, cur.Bag()) != Equal {
+			t.Errorf("expect %d, not %d", i, cur.Bag())
 		}
 		i++
 		cur = tree.NextNode(cur)
@@ -139,8 +139,8 @@ func TestInterationBackward(t *testing.T) {
 			t.Error("too many, should be at less 0")
 		}
 
-		if Compare(i, cur.Bag) != Equal {
-			t.Errorf("expect %d, not %d", i, cur.Bag)
+		if Compare(i, cur.Bag()) != Equal {
+			t.Errorf("expect %d, not %d", i, cur.Bag())
 		}
 		i--
 		cur = tree.PrevNode(cur)
@@ -204,8 +204,8 @@ func TestDupableTreeInteration(t *testing.T) {
 	i := 0
 	cur := tree.MinNode()
 	for cur != tree.Nil {
-		if Compare(MyInt(items[i]), cur.Bag) != Equal {
-			t.Errorf("expect %d, not %d", i, cur.Bag)
+		if Compare(MyInt(items[i]), cur.Bag()) != Equal {
+			t.Errorf("expect %d, not %d", i, cur.Bag())
 		}
 		i++
 		cur = tree.NextNode(cur)
@@ -229,8 +229,8 @@ func TestDupableTreeInterationBackward(t *testing.T) {
 	i := len(items) - 1
 	cur := tree.MaxNode()
 	for cur != tree.Nil {
-		if Compare(MyInt(items[i]), cur.Bag) != Equal {
-			t.Errorf("expect %d, not %d", i, cur.Bag)
+		if Compare(MyInt(items[i]), cur.Bag()) != Equal {
+			t.Errorf("expect %d, not %d", i, cur.Bag())
 		}
 		i--
 		cur = tree.PrevNode(cur)
@@ -275,8 +275,8 @@ func plainDeleteHelper(tree *RBTree, items []int, value int, count int, t *testi
 	i := 0
 	cur := tree.MinNode()
 	for cur != tree.Nil {
-		if Compare(MyInt(items[i]), cur.Bag) != Equal {
-			t.Errorf("expect %d, not %d", i, cur.Bag)
+		if Compare(MyInt(items[i]), cur.Bag()) != Equal {
+			t.Errorf("expect %d, not %d", i, cur.Bag())
 		}
 		i++
 		cur = tree.NextNode(cur)
@@ -306,8 +306,8 @@ func deleteHelper(tree *RBTree, items []int, value int, count int, t *testing.T)
 	i := 0
 	cur := tree.MinNode()
 	for cur != tree.Nil {
-		if Compare(MyInt(items[i]), cur.Bag) != Equal {
-			t.Errorf("expect %d, not %d", i, cur.Bag)
+		if Compare(MyInt(items[i]), cur.Bag()) != Equal {
+			t.Errorf("expect %d, not %d", i, cur.Bag())
 		}
 		i++
 		cur = tree.NextNode(cur)
@@ -471,8 +471,8 @@ func TestDupableTreeRotate(t *testing.T) {
 	next = tree.MinNode()
 	i := 0
 	for ; next != tree.Nil; next = tree.NextNode(next) {
-		if Compare(next.Bag, MyInt(items[i])) != Equal {
-			t.Error("not equal %d v.s. %d", next.Bag, items[i])
+		if Compare(next.Bag(), MyInt(items[i])) != Equal {
+			t.Error("not equal %d v.s. %d", next.Bag(), items[i])
 		}
 		i++
 	}
@@ -519,3 +519,64 @@ func TestDupableTreeFindAfterRotate(t *testing.T) {
 	// 	t.Errorf("verify fail, %s", err.Error())
 	// }
 }
+
+func TestDupableInsertionOrder(t *testing.T) {
+	tree := NewRBTree(true)
+	var bags []*pair
+	for i := 0; i < 200; i++ {
+		bag := &pair{i % 3, string(rune('a' + i%26))}
+		bags = append(bags, bag)
+		tree.Insert(bag)
+	}
+
+	for k := 0; k < 3; k++ {
+		nodes := tree.FindNode(&pair{k: k})
+		if len(nodes) != []int{67, 67, 66}[k] {
+			t.Errorf("unexpected count %d of key %d", len(nodes), k)
+		}
+		for j, n := range nodes {
+			if n.Bag() != bags[j*3+k] {
+				t.Errorf("key %d, %d-th not in insertion order", k, j)
+				break
+			}
+		}
+	}
+
+	// first in, first out within key
+	if err := tree.DeleteFirst(&pair{k: 1}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if err := tree.DeleteLast(&pair{k: 1}); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if got := tree.Find(&pair{k: 1}); got[0] != bags[4] || got[len(got)-1] != bags[196] {
+		t.Error("first or last of key 1 not deleted")
+	}
+	if err := tree.Delete(&pair{k: 1}, false); err != nil || tree.Find(&pair{k: 1})[0] != bags[7] {
+		t.Error("Delete should remove the first")
+	}
+
+	if err := tree.DeleteValue(bags[10]); err != nil {
+		t.Errorf("unexpected error %s", err.Error())
+	}
+	if err := tree.DeleteValue(bags[10]); err == nil {
+		t.Error("expect error for deleted value")
+	}
+	if err := tree.DeleteValue(&pair{1, "k"}); err == nil {
+		t.Error("expect error for value not in tree")
+	}
+	if err := tree.DeleteLast(&pair{k: 5}); err == nil {
+		t.Error("expect error for key not found")
+	}
+	for _, n := range tree.FindNode(&pair{k: 1}) {
+		if n.Bag() == bags[10] {
+			t.Error("bags[10] should be deleted")
+		}
+	}
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+	if tree.Len() != 196 {
+		t.Errorf("expect len 196, really %d", tree.Len())
+	}
+}
@@ -0,0 +1,281 @@
+package rbtree
+
+import (
+	"errors"
+)
+
+// the functions below work on standalone subtrees which share t.Nil, a
+// standalone subtree has a black root whose parent is t.Nil. t.root is
+// used as scratch, callers set root and size when done
+
+// detach makes n a standalone subtree
+func (t *tree[T]) detach(n *node[T]) *node[T] {
+	if n != t.Nil {
+		n.p = t.Nil
+		n.color = Black
+	}
+	return n
+}
+
+// blackHeight counts black nodes from n down to a leaf, sentinel excluded
+func (t *tree[T]) blackHeight(n *node[T]) (h int) {
+	for ; n != t.Nil; n = n.left {
+		if n.color == Black {
+			h++
+		}
+	}
+	return
+}
+
+// join links l, x and r into one subtree, bags of l must not be greater
+// than x, and x not greater than bags of r. x replaces the black node on
+// the spine of the higher tree whose black height matches the lower one,
+// then it is fixed like an inserted node
+func (t *tree[T]) join(l, x, r *node[T]) *node[T] {
+	l, r = t.detach(l), t.detach(r)
+	hl, hr := t.blackHeight(l), t.blackHeight(r)
+
+	x.p = t.Nil
+	x.color = Red
+	switch {
+	case hl == hr:
+		x.color = Black
+		t.root = x
+	case hl > hr:
+		t.root = l
+		for h := hl; l.color == Red || h > hr; l = l.right {
+			if l.color == Black {
+				h--
+			}
+			x.p = l
+		}
+		x.p.right = x
+	default:
+		t.root = r
+		for h := hr; r.color == Red || h > hl; r = r.left {
+			if r.color == Black {
+				h--
+			}
+			x.p = r
+		}
+		x.p.left = x
+	}
+
+	x.left, x.right = l, r
+	if l != t.Nil {
+		l.p = x
+	}
+	if r != t.Nil {
+		r.p = x
+	}
+	t.updateUp(x)
+	t.insertFix(x)
+	return t.root
+}
+
+// join2 is join without a middle node
+func (t *tree[T]) join2(l, r *node[T]) *node[T] {
+	if r == t.Nil {
+		return t.detach(l)
+	}
+	t.root = t.detach(r)
+	m := r
+	for m.left != t.Nil {
+		m = m.left
+	}
+	t.deleteNode(m)
+	return t.join(l, m, t.root)
+}
+
+// split divides subtree n into bags for which less is true and the rest,
+// less must be true for a prefix of bags in order
+func (t *tree[T]) split(n *node[T], less func(bag T) bool) (l, r *node[T]) {
+	if n == t.Nil {
+		return t.Nil, t.Nil
+	}
+	left, right := n.left, n.right
+	if less(n.bag) {
+		l, r = t.split(right, less)
+		return t.join(left, n, l), r
+	}
+	l, r = t.split(left, less)
+	return l, t.join(r, n, right)
+}
+
+// split3 divides subtree n into bags less than, equal to, and greater than key
+func (t *tree[T]) split3(n *node[T], key T, cmp func(a, b T) int) (l, m, r *node[T]) {
+	l, r = t.split(n, func(bag T) bool { return cmp(bag, key) < 0 })
+	m, r = t.split(r, func(bag T) bool { return cmp(bag, key) == 0 })
+	return
+}
+
+// union merges subtrees a and b, for nondupable trees bag from a is kept if
+// both have an equal one
+func (t *tree[T]) union(a, b *node[T], cmp func(a, b T) int, dupable bool) *node[T] {
+	if a == t.Nil {
+		return t.detach(b)
+	}
+	if b == t.Nil {
+		return t.detach(a)
+	}
+	k, bl, br := b, b.left, b.right
+	if dupable {
+		l, r := t.split(a, func(bag T) bool { return cmp(bag, k.bag) < 0 })
+		return t.join(t.union(l, bl, cmp, dupable), k, t.union(r, br, cmp, dupable))
+	}
+	l, m, r := t.split3(a, k.bag, cmp)
+	if m != t.Nil {
+		k.left, k.right, k.p = nil, nil, nil
+		k = m
+	}
+	return t.join(t.union(l, bl, cmp, dupable), k, t.union(r, br, cmp, dupable))
+}
+
+// intersection keeps bags of a which have an equal one in b
+func (t *tree[T]) intersection(a, b *node[T], cmp func(a, b T) int) *node[T] {
+	if a == t.Nil || b == t.Nil {
+		t.release(a)
+		t.release(b)
+		return t.Nil
+	}
+	k, bl, br := b, b.left, b.right
+	k.left, k.right, k.p = nil, nil, nil
+	l, m, r := t.split3(a, k.bag, cmp)
+	return t.join2(t.join2(t.intersection(l, bl, cmp), m), t.intersection(r, br, cmp))
+}
+
+// difference keeps bags of a which have no equal one in b
+func (t *tree[T]) difference(a, b *node[T], cmp func(a, b T) int) *node[T] {
+	if a == t.Nil {
+		t.release(b)
+		return t.Nil
+	}
+	if b == t.Nil {
+		return t.detach(a)
+	}
+	k, bl, br := b, b.left, b.right
+	k.left, k.right, k.p = nil, nil, nil
+	l, m, r := t.split3(a, k.bag, cmp)
+	t.release(m)
+	return t.join2(t.difference(l, bl, cmp), t.difference(r, br, cmp))
+}
+
+// release unlinks every node of subtree n, which is dropped from the
+// result, so handles to them are not owned and may be inserted again
+func (t *tree[T]) release(n *node[T]) {
+	for n != t.Nil {
+		l, r := n.left, n.right
+		n.left, n.right, n.p = nil, nil, nil
+		t.release(l)
+		n = r
+	}
+}
+
+func compareBags(a, b Comparable) int {
+	return int(Compare(a, b))
+}
+
+// consume takes roots of a and b into one tree, a and b are left empty,
+// every RBTree shares the sentinel, so nodes are moved as they are
+func consume(a, b *RBTree) (t *RBTree, ra, rb *RBNode) {
+	t = NewRBTree(a.dupable)
+	ra, rb = a.root, b.root
+	a.reset()
+	b.reset()
+	return
+}
+
+func (t *RBTree) done(root *RBNode) *RBTree {
+	t.root = t.detach(root)
+	t.size = root.count
+	return t
+}
+
+// Clone returns a copy of t in O(n), bags themselves are shared
+func (t *RBTree) Clone() *RBTree {
+	bags := make([]Comparable, 0, t.size)
+	for bag := range t.All() {
+		bags = append(bags, bag)
+	}
+	c := NewRBTree(t.dupable)
+	c.build(bags)
+	return c
+}
+
+// Split moves bags less than key to left and the rest to right in
+// O(log n), t is left empty
+func (t *RBTree) Split(key Comparable) (left, right *RBTree) {
+	l, r := t.split(t.root, func(bag Comparable) bool { return !key.LessEqual(bag) })
+	left, right = NewRBTree(t.dupable), NewRBTree(t.dupable)
+	left.done(l)
+	right.done(r)
+	t.reset()
+	return
+}
+
+// Join concatenates left, key and right in O(log n), every bag of left must not be greater than key,
+// and key not greater than bags of right, strictly for nondupable trees.
+// left and right are left empty
+func Join(left *RBTree, key Comparable, right *RBTree) (*RBTree, error) {
+	if err := checkOperands(left, right); err != nil {
+		return nil, err
+	}
+	if max := left.Max(); max != nil {
+		if rel := Compare(max, key); rel == Greater || (rel == Equal && !left.dupable) {
+			return nil, errors.New("max of left is not less than key")
+		}
+	}
+	if min := right.Min(); min != nil {
+		if rel := Compare(key, min); rel == Greater || (rel == Equal && !right.dupable) {
+			return nil, errors.New("key is not less than min of right")
+		}
+	}
+
+	t, l, r := consume(left, right)
+	return t.done(t.join(l, t.NewRBNode(key, Red), r)), nil
+}
+
+func checkOperands(a, b *RBTree) error {
+	if a == b {
+		return errors.New("same tree as both operands")
+	}
+	if a.dupable != b.dupable {
+		return errors.New("trees of different dupable flag")
+	}
+	return nil
+}
+
+// Union returns a tree of bags in a or b, nodes of a and b are reused and
+// both are left empty, Clone them first if they are still needed. a node
+// not taken into the result is unlinked, so it is owned by no tree and may
+// be inserted again, the same holds for Intersection and Difference.
+//
+// for nondupable trees, bag of a is kept if both have an equal one, for
+// dupable trees every bag of both is kept
+func Union(a, b *RBTree) (*RBTree, error) {
+	if err := checkOperands(a, b); err != nil {
+		return nil, err
+	}
+	t, ra, rb := consume(a, b)
+	return t.done(t.union(ra, rb, compareBags, t.dupable)), nil
+}
+
+// Intersection returns a tree of bags in a which have an equal one in b,
+// for dupable trees all such bags of a are kept, a and b are left empty
+func Intersection(a, b *RBTree) (*RBTree, error) {
+	if err := checkOperands(a, b); err != nil {
+		return nil, err
+	}
+	t, ra, rb := consume(a, b)
+	return t.done(t.intersection(ra, rb, compareBags)), nil
+}
+
+// Difference returns a tree of bags in a which have no equal one in b,
+// a and b are left empty
+func Difference(a, b *RBTree) (*RBTree, error) {
+	if err := checkOperands(a, b); err != nil {
+		return nil, err
+	}
+	t, ra, rb := consume(a, b)
+	return t.done(t.difference(ra, rb, compareBags)), nil
+}
@@ -0,0 +1,233 @@
+package rbtree
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func randomTree(n, max int, dupable bool) (*RBTree, []int) {
+	tree := NewRBTree(dupable)
+	for i := 0; i < n; i++ {
+		tree.Insert(MyInt(rand.Intn(max)))
+	}
+	return tree, collect(tree.All())
+}
+
+func verifyItems(t *testing.T, op string, tree *RBTree, expect []int) {
+	t.Helper()
+	if err := tree.Verify(); err != nil {
+		t.Errorf("%s verify error %s", op, err.Error())
+	}
+	if got := collect(tree.All()); !slices.Equal(got, expect) {
+		t.Errorf("%s expect %v, really %v", op, expect, got)
+	}
+	if tree.Len() != len(expect) {
+		t.Errorf("%s expect len %d, really %d", op, len(expect), tree.Len())
+	}
+}
+
+func TestSplitJoin(t *testing.T) {
+	for _, dupable := range []bool{false, true} {
+		for i := 0; i < 100; i++ {
+			tree, items := randomTree(rand.Intn(200), 100, dupable)
+			key := rand.Intn(110) - 5
+			i := 0
+			for i < len(items) && items[i] < key {
+				i++
+			}
+
+			left, right := tree.Split(MyInt(key))
+			verifyItems(t, "split left", left, items[:i])
+			verifyItems(t, "split right", right, items[i:])
+			verifyItems(t, "split origin", tree, nil)
+
+			// split leaves trees usable
+			left.Insert(MyInt(-10))
+			right.Insert(MyInt(200))
+			left.Delete(MyInt(-10), false)
+			right.Delete(MyInt(200), false)
+			tree.Insert(MyInt(0))
+			verifyItems(t, "split origin reuse", tree, []int{0})
+
+			if dupable || !slices.Contains(items, key) {
+				expect := slices.Insert(slices.Clone(items), i, key)
+				joined, err := Join(left, MyInt(key), right)
+				if err != nil {
+					t.Fatalf("unexpected error %s", err.Error())
+				}
+				verifyItems(t, "join", joined, expect)
+				verifyItems(t, "join left", left, nil)
+				verifyItems(t, "join right", right, nil)
+			} else if _, err := Join(left, MyInt(key), right); err == nil {
+				t.Error("expect error for duplicate key of nondupable tree")
+			}
+		}
+	}
+
+	a, b := NewRBTree(false), NewRBTree(false)
+	a.Insert(MyInt(5))
+	b.Insert(MyInt(3))
+	if _, err := Join(a, MyInt(4), b); err == nil {
+		t.Error("expect error for left greater than key")
+	}
+	if _, err := Join(a, MyInt(4), NewRBTree(true)); err == nil {
+		t.Error("expect error for different dupable flag")
+	}
+	verifyItems(t, "failed join", a, []int{5})
+}
+
+func TestSharedSentinel(t *testing.T) {
+	tree, _ := randomTree(2000, 1000, true)
+	left, right := tree.Split(MyInt(500))
+
+	// halves share the sentinel, mutating them in parallel must not race
+	var wg sync.WaitGroup
+	for i, half := range []*RBTree{left, right} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				if rand.Intn(2) == 0 {
+					half.Insert(MyInt(i*500 + rand.Intn(500)))
+				} else if n := half.MinNode(); n != half.Nil {
+					half.DeleteNode(n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	joined, err := Join(left, MyInt(500), right)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if err := joined.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+
+	if *rbNil != (RBNode{color: Black}) {
+		t.Errorf("sentinel is written: %+v", *rbNil)
+	}
+}
+
+func TestSetOps(t *testing.T) {
+	for _, dupable := range []bool{false, true} {
+		for i := 0; i < 100; i++ {
+			a, as := randomTree(rand.Intn(150), 100, dupable)
+			b, bs := randomTree(rand.Intn(150), 100, dupable)
+
+			var union, inter, diff []int
+			union = append(union, as...)
+			for _, x := range as {
+				if slices.Contains(bs, x) {
+					inter = append(inter, x)
+				} else {
+					diff = append(diff, x)
+				}
+			}
+			for _, x := range bs {
+				if dupable || !slices.Contains(as, x) {
+					union = append(union, x)
+				}
+			}
+			slices.Sort(union)
+
+			res, err := Union(a.Clone(), b.Clone())
+			if err != nil {
+				t.Fatalf("unexpected error %s", err.Error())
+			}
+			verifyItems(t, "union", res, union)
+
+			res, _ = Intersection(a.Clone(), b.Clone())
+			verifyItems(t, "intersection", res, inter)
+
+			ac, bc := a.Clone(), b.Clone()
+			res, _ = Difference(ac, bc)
+			verifyItems(t, "difference", res, diff)
+			verifyItems(t, "consumed", ac, nil)
+			verifyItems(t, "consumed", bc, nil)
+
+			verifyItems(t, "clone origin", a, as)
+		}
+	}
+
+	a := NewRBTree(false)
+	if _, err := Union(a, a); err == nil {
+		t.Error("expect error for same tree as both operands")
+	}
+	if _, err := Difference(a, NewRBTree(true)); err == nil {
+		t.Error("expect error for different dupable flag")
+	}
+}
+
+func nodesOf(tree *RBTree) (nodes []*RBNode) {
+	for n := tree.MinNode(); n != tree.Nil; n = tree.NextNode(n) {
+		nodes = append(nodes, n)
+	}
+	return
+}
+
+func TestSetOpsDroppedNodes(t *testing.T) {
+	ops := map[string]func(a, b *RBTree) (*RBTree, error){
+		"union": Union, "intersection": Intersection, "difference": Difference,
+	}
+	for name, op := range ops {
+		for _, dupable := range []bool{false, true} {
+			for i := 0; i < 20; i++ {
+				a, _ := randomTree(64, 100, dupable)
+				b, _ := randomTree(64, 100, dupable)
+				handles := append(nodesOf(a), nodesOf(b)...)
+				res, _ := op(a, b)
+
+				kept := map[*RBNode]bool{}
+				for _, n := range nodesOf(res) {
+					kept[n] = true
+				}
+				other := NewRBTree(true)
+				for _, h := range handles {
+					if res.Owns(h) != kept[h] {
+						t.Fatalf("%s, owns %v of a node kept %v", name, res.Owns(h), kept[h])
+					}
+					if kept[h] {
+						continue
+					}
+					if err := res.DeleteNode(h); err == nil {
+						t.Fatalf("%s, expect error for deleting dropped node", name)
+					}
+					if err := other.InsertNode(h); err != nil {
+						t.Fatalf("%s, dropped node not reusable: %s", name, err.Error())
+					}
+				}
+				if err := res.Verify(); err != nil {
+					t.Errorf("%s verify error %s", name, err.Error())
+				}
+				if err := other.Verify(); err != nil || other.Len()+res.Len() != len(handles) {
+					t.Errorf("%s, dropped nodes %d, kept %d of %d", name, other.Len(), res.Len(), len(handles))
+				}
+			}
+		}
+	}
+}
+
+func TestUnionKeepsLeft(t *testing.T) {
+	a, b := NewRBTree(false), NewRBTree(false)
+	x, y := &pair{1, "a"}, &pair{1, "b"}
+	a.Insert(x)
+	b.Insert(y)
+	b.Insert(&pair{2, "b"})
+
+	res, _ := Union(a, b)
+	if got := res.Find(&pair{k: 1}); len(got) != 1 || got[0] != x {
+		t.Errorf("bag of a expected, really %v", got)
+	}
+}
+
+type pair struct {
+	k int
+	v string
+}
+
+func (a *pair) LessEqual(b Comparable) bool {
+	return a.k <= b.(*pair).k
+}
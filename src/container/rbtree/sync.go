@@ -0,0 +1,164 @@
+package rbtree
+
+import (
+	"iter"
+	"sync"
+)
+
+// SyncTree is an RBTree safe for concurrent use, reads share a RWMutex,
+// writes hold it exclusively.
+//
+// nodes are never handed out since they would escape the lock, iterators
+// copy bags in range under the read lock before yielding, so they see a
+// consistent snapshot and the loop body is free to modify the tree
+type SyncTree struct {
+	mu sync.RWMutex
+	t  *RBTree
+}
+
+func NewSyncTree(dupable bool) *SyncTree {
+	return &SyncTree{t: NewRBTree(dupable)}
+}
+
+func (s *SyncTree) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Len()
+}
+
+func (s *SyncTree) Insert(comp Comparable) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.Insert(comp)
+}
+
+func (s *SyncTree) Delete(comp Comparable, all bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.t.Delete(comp, all)
+}
+
+func (s *SyncTree) Find(key Comparable) []Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Find(key)
+}
+
+func (s *SyncTree) Min() Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Min()
+}
+
+func (s *SyncTree) Max() Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Max()
+}
+
+func (s *SyncTree) Floor(key Comparable) Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Floor(key)
+}
+
+func (s *SyncTree) Lower(key Comparable) Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Lower(key)
+}
+
+func (s *SyncTree) Ceiling(key Comparable) Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Ceiling(key)
+}
+
+func (s *SyncTree) Higher(key Comparable) Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Higher(key)
+}
+
+func (s *SyncTree) Select(i int) Comparable {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Select(i)
+}
+
+func (s *SyncTree) Rank(key Comparable) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Rank(key)
+}
+
+func (s *SyncTree) CountRange(lo, hi Comparable) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.CountRange(lo, hi)
+}
+
+// View calls fn with the underlying tree under the read lock, fn must not
+// modify the tree nor keep any node after return
+func (s *SyncTree) View(fn func(t *RBTree)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.t)
+}
+
+// Update calls fn with the underlying tree under the write lock, so a
+// sequence of operations is applied atomically
+func (s *SyncTree) Update(fn func(t *RBTree) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.t)
+}
+
+func (s *SyncTree) Verify() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.t.Verify()
+}
+
+// Snapshot returns all bags in order
+func (s *SyncTree) Snapshot() []Comparable {
+	return s.collect(func(t *RBTree) iter.Seq[Comparable] { return t.All() })
+}
+
+func (s *SyncTree) All() iter.Seq[Comparable] {
+	return s.snapshot(func(t *RBTree) iter.Seq[Comparable] { return t.All() })
+}
+
+func (s *SyncTree) Backward() iter.Seq[Comparable] {
+	return s.snapshot(func(t *RBTree) iter.Seq[Comparable] { return t.Backward() })
+}
+
+// Range yields bags between lo and hi in order, nil lo or hi means unbounded
+func (s *SyncTree) Range(lo, hi Comparable, loInclusive, hiInclusive bool) iter.Seq[Comparable] {
+	return s.snapshot(func(t *RBTree) iter.Seq[Comparable] { return t.Range(lo, hi, loInclusive, hiInclusive) })
+}
+
+// From yields bags not less than key in order
+func (s *SyncTree) From(key Comparable) iter.Seq[Comparable] {
+	return s.snapshot(func(t *RBTree) iter.Seq[Comparable] { return t.From(key) })
+}
+
+func (s *SyncTree) collect(seq func(t *RBTree) iter.Seq[Comparable]) (bags []Comparable) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for bag := range seq(s.t) {
+		bags = append(bags, bag)
+	}
+	return
+}
+
+// snapshot is taken when iteration starts, not when the iterator is created
+func (s *SyncTree) snapshot(seq func(t *RBTree) iter.Seq[Comparable]) iter.Seq[Comparable] {
+	return func(yield func(Comparable) bool) {
+		for _, bag := range s.collect(seq) {
+			if !yield(bag) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package rbtree
+
+import (
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSyncTree(t *testing.T) {
+	tree := NewSyncTree(true)
+	for _, item := range []int{5, 1, 3, 3, 9, 7} {
+		tree.Insert(MyInt(item))
+	}
+
+	if got := collect(tree.All()); !slices.Equal(got, []int{1, 3, 3, 5, 7, 9}) {
+		t.Errorf("All not in order, %v", got)
+	}
+	if got := collect(tree.Range(MyInt(3), MyInt(7), false, true)); !slices.Equal(got, []int{5, 7}) {
+		t.Errorf("Range not expected, %v", got)
+	}
+	if tree.Min() != MyInt(1) || tree.Max() != MyInt(9) || tree.Ceiling(MyInt(4)) != MyInt(5) {
+		t.Error("min, max or ceiling not expected")
+	}
+	if tree.Rank(MyInt(5)) != 3 || tree.Select(1) != MyInt(3) || tree.CountRange(MyInt(3), MyInt(7)) != 4 {
+		t.Error("rank, select or count not expected")
+	}
+
+	// the loop body may modify the tree without deadlock
+	for bag := range tree.All() {
+		if err := tree.Delete(bag, false); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+	}
+	if tree.Len() != 0 {
+		t.Errorf("expect empty tree, really %d", tree.Len())
+	}
+}
+
+func TestSyncTreeConcurrent(t *testing.T) {
+	const n = 1000
+	tree := NewSyncTree(false)
+
+	// writers keep i and i+n in the tree together, a torn read would see
+	// one without the other
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				i := MyInt(rand.Intn(n/4)*4 + w)
+				tree.Update(func(rb *RBTree) error {
+					if len(rb.Find(i)) > 0 {
+						rb.Delete(i, false)
+						return rb.Delete(i+n, false)
+					}
+					rb.Insert(i)
+					return rb.Insert(i + n)
+				})
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				items := collect(tree.All())
+				if !slices.IsSorted(items) {
+					t.Errorf("snapshot out of order")
+					return
+				}
+				if len(items)%2 != 0 {
+					t.Errorf("torn snapshot of %d items", len(items))
+					return
+				}
+				half := len(items) / 2
+				for k := 0; k < half; k++ {
+					if items[k]+n != items[k+half] {
+						t.Errorf("torn snapshot, %d without %d", items[k], items[k]+n)
+						return
+					}
+				}
+				if c := tree.CountRange(MyInt(0), MyInt(2*n)); c%2 != 0 {
+					t.Errorf("torn count %d", c)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := tree.Verify(); err != nil {
+		t.Errorf("verify error %s", err.Error())
+	}
+}
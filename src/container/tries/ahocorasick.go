@@ -0,0 +1,152 @@
+package tries
+
+import (
+	"bufio"
+	"io"
+	"slices"
+)
+
+// Occurrence is a pattern found in the input, Start and End are byte
+// offsets, End is exclusive
+type Occurrence struct {
+	Pattern string
+	Start   int
+	End     int
+}
+
+type acEdge struct {
+	b  byte
+	to int32
+}
+
+type acState struct {
+	edges []acEdge // goto function, sorted by b
+	fail  int32
+	// out is the nearest state on the fail chain which ends a pattern,
+	// -1 if none
+	out     int32
+	pattern string // not empty if a pattern ends here
+}
+
+// Automaton is an Aho-Corasick automaton compiled from a Tries, it works
+// on bytes, since utf-8 is self-synchronizing, every occurrence of a
+// Unicode word starts on a rune boundary as well
+type Automaton struct {
+	states []acState
+}
+
+func (a *Automaton) goTo(s int32, b byte) (int32, bool) {
+	edges := a.states[s].edges
+	if i, found := slices.BinarySearchFunc(edges, b, func(e acEdge, b byte) int {
+		return int(e.b) - int(b)
+	}); found {
+		return edges[i].to, true
+	}
+	return 0, false
+}
+
+// Compile builds an Aho-Corasick automaton for all words of t, later
+// changes of t are not reflected
+func (t *Tries) Compile() *Automaton {
+	a := &Automaton{states: []acState{{out: -1}}}
+
+	// goto function, words are walked in lexicographic order, so edges are
+	// appended sorted
+	t.walk(nil, t.alphabet, func(word []byte, _ *Node) bool {
+		s := int32(0)
+		for _, b := range word {
+			to, ok := a.goTo(s, b)
+			if !ok {
+				to = int32(len(a.states))
+				a.states = append(a.states, acState{out: -1})
+				a.states[s].edges = append(a.states[s].edges, acEdge{b, to})
+			}
+			s = to
+		}
+		a.states[s].pattern = string(word)
+		return true
+	})
+
+	// fail and out links in breadth first order
+	queue := []int32{0}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, e := range a.states[s].edges {
+			child := &a.states[e.to]
+			if s != 0 {
+				child.fail = a.step(a.states[s].fail, e.b)
+			}
+			if f := &a.states[child.fail]; f.pattern != "" {
+				child.out = child.fail
+			} else {
+				child.out = f.out
+			}
+			queue = append(queue, e.to)
+		}
+	}
+	return a
+}
+
+func (a *Automaton) step(s int32, b byte) int32 {
+	for {
+		if to, ok := a.goTo(s, b); ok {
+			return to
+		}
+		if s == 0 {
+			return 0
+		}
+		s = a.states[s].fail
+	}
+}
+
+// emit reports patterns end at state s, longest first
+func (a *Automaton) emit(s int32, end int, yield func(Occurrence) bool) bool {
+	if a.states[s].pattern == "" {
+		s = a.states[s].out
+	}
+	for ; s >= 0; s = a.states[s].out {
+		p := a.states[s].pattern
+		if !yield(Occurrence{Pattern: p, Start: end - len(p), End: end}) {
+			return false
+		}
+	}
+	return true
+}
+
+// Scan returns every occurrence in s, ordered by End, then longest first
+func (a *Automaton) Scan(s string) (res []Occurrence) {
+	state := int32(0)
+	for i := 0; i < len(s); i++ {
+		state = a.step(state, s[i])
+		a.emit(state, i+1, func(o Occurrence) bool {
+			res = append(res, o)
+			return true
+		})
+	}
+	return
+}
+
+// ScanReader reports every occurrence in the stream in the same order as
+// Scan, offsets are counted from the beginning of r, scanning stops when
+// yield returns false
+func (a *Automaton) ScanReader(r io.Reader, yield func(Occurrence) bool) error {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	state := int32(0)
+	for i := 0; ; i++ {
+		b, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		state = a.step(state, b)
+		if !a.emit(state, i+1, yield) {
+			return nil
+		}
+	}
+}
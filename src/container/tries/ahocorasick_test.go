@@ -0,0 +1,96 @@
+package tries
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func bruteScan(patterns []string, s string) (res []Occurrence) {
+	for end := 1; end <= len(s); end++ {
+		var found []Occurrence
+		for _, p := range patterns {
+			if strings.HasSuffix(s[:end], p) {
+				found = append(found, Occurrence{p, end - len(p), end})
+			}
+		}
+		slices.SortFunc(found, func(x, y Occurrence) int { return len(y.Pattern) - len(x.Pattern) })
+		res = append(res, found...)
+	}
+	return
+}
+
+func TestAutomatonScan(t *testing.T) {
+	tree := NewTries()
+	patterns := []string{"he", "she", "his", "hers", "s"}
+	for _, p := range patterns {
+		tree.Insert(p)
+	}
+	a := tree.Compile()
+
+	res := a.Scan("ushers")
+	expect := []Occurrence{{"she", 1, 4}, {"he", 2, 4}, {"s", 1, 2}, {"hers", 2, 6}, {"s", 5, 6}}
+	slices.SortStableFunc(expect, func(x, y Occurrence) int { return x.End - y.End })
+	if !slices.Equal(res, expect) {
+		t.Errorf("expect %v, really %v", expect, res)
+	}
+
+	if res := a.Scan(""); len(res) != 0 {
+		t.Errorf("not expected: %v", res)
+	}
+	if res := NewTries().Compile().Scan("abc"); len(res) != 0 {
+		t.Errorf("not expected: %v", res)
+	}
+}
+
+func TestAutomatonRandom(t *testing.T) {
+	letters := []rune("abé日")
+	tree := NewTriesWith(Unicode)
+	var patterns []string
+	for i := 0; i < 50; i++ {
+		p := randomWord(letters, 4)
+		if !tree.Match(p) {
+			tree.Insert(p)
+			patterns = append(patterns, p)
+		}
+	}
+	a := tree.Compile()
+
+	for i := 0; i < 50; i++ {
+		s := randomWord(letters, 100)
+		expect := bruteScan(patterns, s)
+		if res := a.Scan(s); !slices.Equal(res, expect) {
+			t.Errorf("scan %s expect %v, really %v", s, expect, res)
+		}
+
+		var res []Occurrence
+		err := a.ScanReader(iotest.OneByteReader(strings.NewReader(s)), func(o Occurrence) bool {
+			res = append(res, o)
+			return true
+		})
+		if err != nil || !slices.Equal(res, expect) {
+			t.Errorf("scan reader %s expect %v, really %v", s, expect, res)
+		}
+	}
+}
+
+func TestAutomatonScanReaderStop(t *testing.T) {
+	tree := NewTries()
+	tree.Insert("a")
+	a := tree.Compile()
+
+	n := 0
+	err := a.ScanReader(strings.NewReader("aaaaa"), func(o Occurrence) bool {
+		n++
+		return n < 2
+	})
+	if err != nil || n != 2 {
+		t.Errorf("expect stop after 2, really %d", n)
+	}
+
+	err = a.ScanReader(iotest.ErrReader(iotest.ErrTimeout), func(Occurrence) bool { return true })
+	if err != iotest.ErrTimeout {
+		t.Errorf("expect error from reader, really %v", err)
+	}
+}
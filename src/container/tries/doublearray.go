@@ -0,0 +1,232 @@
+package tries
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// daUnit is a state of the double-array, transition from state s on byte c
+// goes to t = base[s] + c if check[t] == s + 1, zero check means free
+type daUnit struct {
+	base  int32
+	check int32
+}
+
+// daMeta is kept apart from units so lookups touch only 8 bytes a state,
+// first and sibling link children in order for enumeration, -1 for none
+type daMeta struct {
+	first   int16
+	sibling int16
+	exists  bool
+}
+
+// DoubleArray is a static trie in base/check arrays, transitions are on
+// bytes of words, so a lookup is two array reads a byte without chasing
+// pointers. it answers the same as a Tries of the same words
+type DoubleArray struct {
+	units    []daUnit
+	meta     []daMeta
+	alphabet Alphabet
+	size     int
+	// nextCheck is where the search of a free base starts
+	nextCheck int
+}
+
+// NewDoubleArray builds from words, which need not be sorted nor unique,
+// an error is returned if any word is empty or not allowed by a
+func NewDoubleArray(words []string, a Alphabet) (*DoubleArray, error) {
+	for _, word := range words {
+		if word == "" {
+			return nil, errors.New("empty word")
+		}
+		if !a.valid(word) {
+			return nil, fmt.Errorf("%s has chars not allowed", word)
+		}
+	}
+	words = slices.Clone(words)
+	slices.Sort(words)
+	return newDoubleArray(slices.Compact(words), a), nil
+}
+
+// DoubleArray builds a double-array trie of words in t
+func (t *Tries) DoubleArray() *DoubleArray {
+	return newDoubleArray(t.dump(nil, t.alphabet), t.alphabet)
+}
+
+// words must be sorted, unique and valid
+func newDoubleArray(words []string, a Alphabet) *DoubleArray {
+	d := &DoubleArray{alphabet: a, size: len(words), nextCheck: 1}
+	d.grow(1)
+	d.units[0].check = -1 // root is never free
+	d.build(0, words, 0)
+	d.units[0].check = 0
+
+	// drop free tail
+	l := len(d.units)
+	for l > 1 && d.units[l-1].check == 0 {
+		l--
+	}
+	d.units, d.meta = slices.Clip(d.units[:l]), slices.Clip(d.meta[:l])
+	return d
+}
+
+func (d *DoubleArray) grow(n int) {
+	for len(d.units) < n {
+		d.units = append(d.units, daUnit{})
+		d.meta = append(d.meta, daMeta{first: -1, sibling: -1})
+	}
+}
+
+// build fills state s for words sharing a prefix of depth bytes
+func (d *DoubleArray) build(s int, words []string, depth int) {
+	if len(words) > 0 && len(words[0]) == depth {
+		d.meta[s].exists = true
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return
+	}
+
+	var labels []byte
+	for _, word := range words {
+		if c := word[depth]; len(labels) == 0 || labels[len(labels)-1] != c {
+			labels = append(labels, c)
+		}
+	}
+
+	base := d.findBase(labels)
+	d.units[s].base = int32(base)
+	for i, c := range labels {
+		d.units[base+int(c)].check = int32(s + 1)
+		if i == 0 {
+			d.meta[s].first = int16(c)
+		} else {
+			d.meta[base+int(labels[i-1])].sibling = int16(c)
+		}
+	}
+
+	for lo := 0; lo < len(words); {
+		c := words[lo][depth]
+		hi := lo + 1
+		for hi < len(words) && words[hi][depth] == c {
+			hi++
+		}
+		d.build(base+int(c), words[lo:hi], depth+1)
+		lo = hi
+	}
+}
+
+// findBase returns a base whose slots for labels are all free, the start
+// of search is moved forward once the array before it is dense enough
+func (d *DoubleArray) findBase(labels []byte) int {
+	first := int(labels[0])
+	pos := max(d.nextCheck, first+1)
+	free, scanned := -1, 0
+	for ; ; pos++ {
+		d.grow(pos + 256)
+		if d.units[pos].check != 0 {
+			scanned++
+			continue
+		}
+		if free < 0 {
+			free = pos
+		}
+		base := pos - first
+		if !slices.ContainsFunc(labels[1:], func(c byte) bool { return d.units[base+int(c)].check != 0 }) {
+			break
+		}
+	}
+	if free >= 0 && d.nextCheck < free {
+		d.nextCheck = free
+	}
+	if scanned > 0 && float64(scanned)/float64(pos-d.nextCheck+1) > 0.95 {
+		d.nextCheck = pos
+	}
+	return pos - first
+}
+
+func (d *DoubleArray) Len() int {
+	return d.size
+}
+
+// next returns state from s on byte c, -1 if no such transition
+func (d *DoubleArray) next(s int, c byte) int {
+	t := int(d.units[s].base) + int(c)
+	if t < len(d.units) && d.units[t].check == int32(s+1) {
+		return t
+	}
+	return -1
+}
+
+// find walks down along str from root, -1 if no such path
+func (d *DoubleArray) find(str string) int {
+	s := 0
+	for i := 0; i < len(str) && s >= 0; i++ {
+		s = d.next(s, str[i])
+	}
+	return s
+}
+
+func (d *DoubleArray) Match(str string) bool {
+	if str == "" {
+		return false
+	}
+	s := d.find(str)
+	return s >= 0 && d.meta[s].exists
+}
+
+// MatchPartial returns words start with str in lexicographic order
+func (d *DoubleArray) MatchPartial(str string) (res []string) {
+	// a Tries can not stop inside a symbol, neither should we
+	if !d.alphabet.valid(str) {
+		return
+	}
+	s := d.find(str)
+	if s < 0 {
+		return
+	}
+	d.walk(s, []byte(str), func(word []byte) {
+		res = append(res, string(word))
+	})
+	return
+}
+
+func (d *DoubleArray) walk(s int, prefix []byte, yield func(word []byte)) {
+	if d.meta[s].exists {
+		yield(prefix)
+	}
+	base := int(d.units[s].base)
+	for c := d.meta[s].first; c >= 0; c = d.meta[base+int(c)].sibling {
+		d.walk(base+int(c), append(prefix, byte(c)), yield)
+	}
+}
+
+// prefixes calls yield with byte length of every word that is a prefix of s
+func (d *DoubleArray) prefixes(str string, yield func(l int)) {
+	s := 0
+	for i := 0; i < len(str); i++ {
+		if s = d.next(s, str[i]); s < 0 {
+			return
+		}
+		if d.meta[s].exists {
+			yield(i + 1)
+		}
+	}
+}
+
+// LongestPrefixOf returns the longest word which is a prefix of s
+func (d *DoubleArray) LongestPrefixOf(s string) (prefix string, ok bool) {
+	d.prefixes(s, func(l int) {
+		prefix, ok = s[:l], true
+	})
+	return
+}
+
+// PrefixesOf returns all words which are prefix of s, shortest first
+func (d *DoubleArray) PrefixesOf(s string) (prefixes []string) {
+	d.prefixes(s, func(l int) {
+		prefixes = append(prefixes, s[:l])
+	})
+	return
+}
@@ -0,0 +1,155 @@
+package tries
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestDoubleArray(t *testing.T) {
+	words := []string{"a", "ab", "abc", "abd", "hello", "help", "world", "worlds", "ab"}
+	d, err := NewDoubleArray(words, LowerCase)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if d.Len() != 8 {
+		t.Errorf("expect len 8, really %d", d.Len())
+	}
+	for _, word := range words {
+		if !d.Match(word) {
+			t.Errorf("%s should match", word)
+		}
+	}
+	for _, word := range []string{"", "abcd", "hel", "x", "worldss"} {
+		if d.Match(word) {
+			t.Errorf("%s should not match", word)
+		}
+	}
+	if got := d.MatchPartial("ab"); !slices.Equal(got, []string{"ab", "abc", "abd"}) {
+		t.Errorf("not expected: %v", got)
+	}
+	if got := d.PrefixesOf("abcde"); !slices.Equal(got, []string{"a", "ab", "abc"}) {
+		t.Errorf("not expected: %v", got)
+	}
+	if p, ok := d.LongestPrefixOf("worldsmith"); !ok || p != "worlds" {
+		t.Errorf("expect worlds, really %s", p)
+	}
+
+	if _, err := NewDoubleArray([]string{"ok", "Bad"}, LowerCase); err == nil {
+		t.Error("expect error for chars not allowed")
+	}
+	if _, err := NewDoubleArray([]string{""}, LowerCase); err == nil {
+		t.Error("expect error for empty word")
+	}
+	if d, _ := NewDoubleArray(nil, LowerCase); d.Match("a") || len(d.MatchPartial("")) != 0 {
+		t.Error("empty double array expected")
+	}
+}
+
+func TestDoubleArraySameAsTries(t *testing.T) {
+	for _, c := range []struct {
+		a       Alphabet
+		letters []rune
+	}{
+		{LowerCase, []rune("abcd")},
+		{Unicode, []rune("aé中\U0001F600")},
+		{Binary, []rune{0, 'a', 0xff}},
+	} {
+		tr := NewTriesWith(c.a)
+		word := func() string {
+			w := randomWord(c.letters, 6)
+			if c.a == Binary {
+				b := []byte{}
+				for _, r := range w {
+					b = append(b, byte(r))
+				}
+				w = string(b)
+			}
+			return w
+		}
+		for i := 0; i < 500; i++ {
+			tr.Insert(word())
+		}
+		d := tr.DoubleArray()
+
+		for i := 0; i < 500; i++ {
+			s := word()
+			// cut anywhere, maybe inside a rune
+			p := s[:rand.Intn(len(s)+1)]
+			if d.Match(s) != tr.Match(s) {
+				t.Errorf("alphabet %d, Match %q not expected", c.a, s)
+			}
+			if got, expect := d.MatchPartial(p), tr.MatchPartial(p); !slices.Equal(got, expect) {
+				t.Errorf("alphabet %d, MatchPartial %q expect %v, really %v", c.a, p, expect, got)
+			}
+			if got, expect := d.PrefixesOf(s), tr.PrefixesOf(s); !slices.Equal(got, expect) {
+				t.Errorf("alphabet %d, PrefixesOf %q expect %v, really %v", c.a, s, expect, got)
+			}
+			lp, ok := d.LongestPrefixOf(s)
+			if elp, eok := tr.LongestPrefixOf(s); lp != elp || ok != eok {
+				t.Errorf("alphabet %d, LongestPrefixOf %q expect %q, really %q", c.a, s, elp, lp)
+			}
+		}
+	}
+}
+
+func benchWords() (words, queries []string) {
+	words = dictionary(100000)
+	for i := 0; i < 1000; i++ {
+		if i%2 == 0 {
+			queries = append(queries, words[rand.Intn(len(words))])
+		} else {
+			queries = append(queries, dictionary(1)[0])
+		}
+	}
+	return
+}
+
+func BenchmarkTriesMatch(b *testing.B) {
+	words, queries := benchWords()
+	tr := NewTries()
+	for _, word := range words {
+		tr.Insert(word)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Match(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkDoubleArrayMatch(b *testing.B) {
+	words, queries := benchWords()
+	d, _ := NewDoubleArray(words, LowerCase)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Match(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkTriesLongestPrefixOf(b *testing.B) {
+	words, queries := benchWords()
+	tr := NewTries()
+	for _, word := range words {
+		tr.Insert(word)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.LongestPrefixOf(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkDoubleArrayLongestPrefixOf(b *testing.B) {
+	words, queries := benchWords()
+	d, _ := NewDoubleArray(words, LowerCase)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.LongestPrefixOf(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkDoubleArrayBuild(b *testing.B) {
+	words, _ := benchWords()
+	for i := 0; i < b.N; i++ {
+		NewDoubleArray(words, LowerCase)
+	}
+}
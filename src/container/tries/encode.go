@@ -0,0 +1,266 @@
+package tries
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"unicode/utf8"
+)
+
+// format of a marshaled Tries, integers are uvarint unless noted:
+//
+//	magic "TRI", version byte, alphabet byte
+//	hmin, hmax as varint
+//	count of nodes
+//	nodes in level order, root first, each as
+//	    count of children << 1 | exists
+//	    labels of children in order, a byte each for LowerCase and Binary,
+//	    uvarint for Unicode
+//	crc32 (IEEE) of all above, 4 bytes big endian
+//
+// children of a node follow children of nodes before it at the next level,
+// so no pointer is stored, nodes are loaded into a few large slices
+
+const (
+	encodeMagic   = "TRI"
+	encodeVersion = 1
+)
+
+func (t *Tries) MarshalBinary() ([]byte, error) {
+	data := append([]byte(encodeMagic), encodeVersion, byte(t.alphabet))
+	data = binary.AppendVarint(data, int64(t.hmin))
+	data = binary.AppendVarint(data, int64(t.hmax))
+
+	// labels for a node are appended to nodes, which is then appended
+	// after the count is known
+	var nodes []byte
+	count := 0
+	queue := []*Node{&t.Node}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		count++
+
+		k := 0
+		for _, c := range n.children {
+			if c != nil {
+				k++
+			}
+		}
+		v := uint64(k) << 1
+		if n.exists {
+			v |= 1
+		}
+		nodes = binary.AppendUvarint(nodes, v)
+		for i, c := range n.children {
+			if c == nil {
+				continue
+			}
+			if t.alphabet == Unicode {
+				nodes = binary.AppendUvarint(nodes, uint64(n.label(i)))
+			} else {
+				nodes = append(nodes, byte(n.label(i)))
+			}
+			queue = append(queue, c)
+		}
+	}
+
+	data = binary.AppendUvarint(data, uint64(count))
+	data = append(data, nodes...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// validSymbol reports whether c can be a label under a
+func (a Alphabet) validSymbol(c rune) bool {
+	switch a {
+	case Binary:
+		return c <= math.MaxUint8
+	case Unicode:
+		return utf8.ValidRune(c)
+	default:
+		return 'a' <= c && c <= 'z'
+	}
+}
+
+// decoder reads data, a failed read sets err and every later read
+// returns zero
+type decoder struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (d *decoder) fail(format string, args ...any) {
+	if d.err == nil {
+		d.err = fmt.Errorf(format+" at offset %d", append(args, d.off)...)
+	}
+}
+
+func (d *decoder) byte(what string) byte {
+	if d.err != nil {
+		return 0
+	}
+	if d.off >= len(d.data) {
+		d.fail("truncated data reading %s", what)
+		return 0
+	}
+	d.off++
+	return d.data[d.off-1]
+}
+
+func (d *decoder) uvarint(what string) uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data[d.off:])
+	if n <= 0 {
+		d.fail("bad or truncated %s", what)
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+func (d *decoder) varint(what string) int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data[d.off:])
+	if n <= 0 {
+		d.fail("bad or truncated %s", what)
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+// UnmarshalBinary replaces content of t with data made by MarshalBinary,
+// data is validated against the checksum and trie invariants
+func (t *Tries) UnmarshalBinary(data []byte) error {
+	if len(data) < len(encodeMagic)+2+4 {
+		return errors.New("truncated data")
+	}
+	if string(data[:len(encodeMagic)]) != encodeMagic {
+		return errors.New("not a marshaled tries, bad magic")
+	}
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(sum) {
+		return errors.New("checksum mismatch, data is corrupted")
+	}
+
+	d := &decoder{data: body, off: len(encodeMagic)}
+	if v := d.byte("version"); v != encodeVersion {
+		return fmt.Errorf("unsupported version %d", v)
+	}
+	a := Alphabet(d.byte("alphabet"))
+	if a != LowerCase && a != Unicode && a != Binary {
+		return fmt.Errorf("unknown alphabet %d", a)
+	}
+	hmin, hmax := d.varint("hmin"), d.varint("hmax")
+	count := d.uvarint("count of nodes")
+	if d.err != nil {
+		return d.err
+	}
+	// every node takes at least a byte
+	if count == 0 || count > uint64(len(body)-d.off) {
+		return fmt.Errorf("bad count of nodes %d", count)
+	}
+
+	// all nodes, child pointers and labels are carved from a few slices
+	nodes := make([]Node, count)
+	depth := make([]int32, count)
+	var ptrs []*Node
+	var labels []rune
+	var counts []int
+	next := 1
+	for i := range nodes {
+		n := &nodes[i]
+		v := d.uvarint("node")
+		k, exists := int(v>>1), v&1 == 1
+		if d.err != nil {
+			return d.err
+		}
+		if k > len(nodes)-next {
+			return fmt.Errorf("node %d has more children than nodes left", i)
+		}
+		if i == 0 && exists {
+			return errors.New("root holds empty word")
+		}
+		if i > 0 && k == 0 && !exists {
+			return fmt.Errorf("node %d is an empty leaf", i)
+		}
+
+		n.exists = exists
+		if exists {
+			h := int(depth[i])
+			for len(counts) <= h {
+				counts = append(counts, 0)
+			}
+			counts[h]++
+		}
+		if k == 0 {
+			continue
+		}
+
+		if a == LowerCase {
+			if len(ptrs) < RunWidth {
+				ptrs = make([]*Node, RunWidth*min(len(nodes)-next, 1024))
+			}
+			n.children, ptrs = ptrs[:RunWidth:RunWidth], ptrs[RunWidth:]
+		} else {
+			if len(ptrs) < k {
+				ptrs = make([]*Node, max(k, min(len(nodes)-next, 4096)))
+				labels = make([]rune, len(ptrs))
+			}
+			n.children, ptrs = ptrs[:k:k], ptrs[k:]
+			n.labels, labels = labels[:k:k], labels[k:]
+		}
+
+		prev := rune(-1)
+		for j := 0; j < k; j++ {
+			var c rune
+			if a == Unicode {
+				u := d.uvarint("label")
+				if u > utf8.MaxRune {
+					d.fail("bad label %d", u)
+				}
+				c = rune(u)
+			} else {
+				c = rune(d.byte("label"))
+			}
+			if d.err != nil {
+				return d.err
+			}
+			if c <= prev || !a.validSymbol(c) {
+				return fmt.Errorf("bad label %q of node %d", c, i)
+			}
+			prev = c
+
+			child := &nodes[next]
+			depth[next] = depth[i] + 1
+			next++
+			if a == LowerCase {
+				n.children[c-'a'] = child
+			} else {
+				n.labels[j] = c
+				n.children[j] = child
+			}
+		}
+	}
+	if d.off != len(body) {
+		return fmt.Errorf("%d bytes left after nodes", len(body)-d.off)
+	}
+	if next != len(nodes) {
+		return fmt.Errorf("%d nodes unreachable", len(nodes)-next)
+	}
+
+	loaded := Tries{Node: nodes[0], alphabet: a, counts: counts}
+	loaded.fixHeight()
+	if int64(loaded.hmin) != hmin || int64(loaded.hmax) != hmax {
+		return fmt.Errorf("hmin %d, hmax %d do not match words", hmin, hmax)
+	}
+	*t = loaded
+	return nil
+}
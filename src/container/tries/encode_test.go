@@ -0,0 +1,126 @@
+package tries
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	for _, c := range []struct {
+		a       Alphabet
+		letters []rune
+	}{
+		{LowerCase, []rune("abcxyz")},
+		{Unicode, []rune("aé中文\U0001F600")},
+		{Binary, []rune{0, 'a', 0x7f, 0xff}},
+	} {
+		tr := NewTriesWith(c.a)
+		for i := 0; i < 500; i++ {
+			word := randomWord(c.letters, 8)
+			if c.a == Binary {
+				b := []byte{}
+				for _, r := range word {
+					b = append(b, byte(r))
+				}
+				word = string(b)
+			}
+			tr.Insert(word)
+		}
+		words := tr.dump(nil, c.a)
+
+		data, err := tr.MarshalBinary()
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		loaded := NewTries()
+		if err := loaded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		if got := loaded.dump(nil, c.a); !slices.Equal(got, words) {
+			t.Errorf("alphabet %d, words not expected", c.a)
+		}
+		if loaded.hmin != tr.hmin || loaded.hmax != tr.hmax || loaded.alphabet != c.a {
+			t.Errorf("alphabet %d, expect hmin %d hmax %d, really %d %d", c.a, tr.hmin, tr.hmax, loaded.hmin, loaded.hmax)
+		}
+
+		// loaded tries is fully functional
+		for _, word := range words[:len(words)/2] {
+			if !loaded.Match(word) || !loaded.Delete(word) {
+				t.Errorf("alphabet %d, %q should be deleted", c.a, word)
+			}
+		}
+		loaded.DeletePrefix("")
+		if loaded.hmax >= 0 || !loaded.empty() {
+			t.Errorf("alphabet %d, expect empty tries", c.a)
+		}
+		if err := loaded.Insert(words[0]); err != nil || !loaded.Match(words[0]) {
+			t.Errorf("alphabet %d, insert after load failed", c.a)
+		}
+	}
+
+	empty, _ := NewTries().MarshalBinary()
+	loaded := NewTries()
+	if err := loaded.UnmarshalBinary(empty); err != nil || !loaded.empty() || loaded.hmin != NewTries().hmin {
+		t.Errorf("empty tries not loaded, %v", err)
+	}
+}
+
+func TestUnmarshalBinaryCorrupted(t *testing.T) {
+	tr := NewTries()
+	for _, word := range []string{"hello", "help", "world", "a", "ab"} {
+		tr.Insert(word)
+	}
+	data, _ := tr.MarshalBinary()
+
+	for i := 0; i < len(data); i++ {
+		if err := NewTries().UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("truncated at %d, expect error", i)
+		}
+		corrupted := slices.Clone(data)
+		corrupted[i] ^= 0x01
+		if err := NewTries().UnmarshalBinary(corrupted); err == nil {
+			t.Errorf("corrupted at %d, expect error", i)
+		}
+	}
+
+	// failed unmarshal leaves t untouched
+	if err := tr.UnmarshalBinary(data[:len(data)-1]); err == nil || !tr.Match("help") {
+		t.Error("tries changed by failed unmarshal")
+	}
+	if err := tr.UnmarshalBinary([]byte("XYZ\x01\x00\x00\x00\x00\x00\x00\x00")); err == nil || !strings.Contains(err.Error(), "bad magic") {
+		t.Errorf("expect bad magic, really %v", err)
+	}
+}
+
+func dictionary(n int) (words []string) {
+	letters := []rune("abcdefghijklmnopqrstuvwxyz")
+	for i := 0; i < n; i++ {
+		words = append(words, randomWord(letters, 12))
+	}
+	return
+}
+
+func BenchmarkUnmarshalBinary(b *testing.B) {
+	tr := NewTries()
+	for _, word := range dictionary(100000) {
+		tr.Insert(word)
+	}
+	data, _ := tr.MarshalBinary()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewTries().UnmarshalBinary(data)
+	}
+}
+
+func BenchmarkReplayInsert(b *testing.B) {
+	words := dictionary(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr := NewTries()
+		for _, word := range words {
+			tr.Insert(word)
+		}
+	}
+}
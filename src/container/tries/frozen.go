@@ -0,0 +1,240 @@
+package tries
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// layout of a frozen trie, all integers are uint32 little endian:
+//
+//	header: magic "FTRI", version byte, alphabet byte, 2 bytes zero,
+//	        count of nodes, count of words
+//	nodes:  count times: index of first child, count of children << 1 | exists
+//	labels: count times: symbol leads to the node, 0 for root
+//
+// nodes are in level order, so children of a node are adjacent and sorted
+// by label, a child is found by binary search over labels, every query
+// reads the bytes in place without decoding
+
+const (
+	frozenMagic   = "FTRI"
+	frozenVersion = 1
+	frozenHeader  = 16
+	frozenNode    = 8
+	frozenLabel   = 4
+)
+
+// Frozen is a read-only trie over a byte image, which is usually mapped
+// from a file by OpenFrozen, so it costs nearly nothing to open and the
+// memory is shared between processes
+type Frozen struct {
+	nodes    []byte
+	labels   []byte
+	count    uint32
+	words    int
+	alphabet Alphabet
+	close    func() error
+}
+
+// Freeze returns the frozen image of t, write it to a file to be opened
+// by OpenFrozen
+func (t *Tries) Freeze() []byte {
+	var nodes, labels []byte
+	count, words := uint32(0), 0
+	next := uint32(1)
+	queue := []*Node{&t.Node}
+	labels = binary.LittleEndian.AppendUint32(labels, 0)
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		count++
+
+		k := uint32(0)
+		for i, c := range n.children {
+			if c != nil {
+				labels = binary.LittleEndian.AppendUint32(labels, uint32(n.label(i)))
+				queue = append(queue, c)
+				k++
+			}
+		}
+		v := k << 1
+		if n.exists {
+			v |= 1
+			words++
+		}
+		nodes = binary.LittleEndian.AppendUint32(nodes, next)
+		nodes = binary.LittleEndian.AppendUint32(nodes, v)
+		next += k
+	}
+
+	data := append([]byte(frozenMagic), frozenVersion, byte(t.alphabet), 0, 0)
+	data = binary.LittleEndian.AppendUint32(data, count)
+	data = binary.LittleEndian.AppendUint32(data, uint32(words))
+	data = append(data, nodes...)
+	return append(data, labels...)
+}
+
+// NewFrozen opens a frozen image in memory, data is used in place and must
+// not be modified. only the header and size are checked here, a corrupted
+// image never makes a query panic, nor visit more nodes than the image
+// has, but may give wrong results
+func NewFrozen(data []byte) (*Frozen, error) {
+	if len(data) < frozenHeader {
+		return nil, errors.New("truncated frozen header")
+	}
+	if string(data[:len(frozenMagic)]) != frozenMagic {
+		return nil, errors.New("not a frozen trie, bad magic")
+	}
+	if v := data[4]; v != frozenVersion {
+		return nil, fmt.Errorf("unsupported version %d", v)
+	}
+	a := Alphabet(data[5])
+	if a != LowerCase && a != Unicode && a != Binary {
+		return nil, fmt.Errorf("unknown alphabet %d", a)
+	}
+	count := binary.LittleEndian.Uint32(data[8:])
+	if count == 0 || uint64(len(data)) != frozenHeader+uint64(count)*(frozenNode+frozenLabel) {
+		return nil, fmt.Errorf("size %d does not match %d nodes", len(data), count)
+	}
+
+	nodes := frozenHeader + int(count)*frozenNode
+	return &Frozen{
+		nodes:    data[frozenHeader:nodes],
+		labels:   data[nodes:],
+		count:    count,
+		words:    int(binary.LittleEndian.Uint32(data[12:])),
+		alphabet: a,
+	}, nil
+}
+
+// Close releases the mapping, f must not be used after
+func (f *Frozen) Close() error {
+	if f.close == nil {
+		return nil
+	}
+	err := f.close()
+	f.close, f.nodes, f.labels, f.count = nil, nil, nil, 0
+	return err
+}
+
+func (f *Frozen) Len() int {
+	return f.words
+}
+
+// node returns children range of node i, an out of range one is treated
+// as having no child, so is one not after i, which is never the case in
+// level order, thus a path down never comes back to a node
+func (f *Frozen) node(i uint32) (first, k uint32, exists bool) {
+	v := binary.LittleEndian.Uint32(f.nodes[int(i)*frozenNode+4:])
+	first, k = binary.LittleEndian.Uint32(f.nodes[int(i)*frozenNode:]), v>>1
+	if first <= i || first > f.count || k > f.count-first {
+		k = 0
+	}
+	return first, k, v&1 == 1
+}
+
+func (f *Frozen) label(i uint32) rune {
+	return rune(binary.LittleEndian.Uint32(f.labels[int(i)*frozenLabel:]))
+}
+
+func (f *Frozen) child(i uint32, c rune) (uint32, bool) {
+	first, k, _ := f.node(i)
+	j := uint32(sort.Search(int(k), func(j int) bool { return f.label(first+uint32(j)) >= c }))
+	if j < k && f.label(first+j) == c {
+		return first + j, true
+	}
+	return 0, false
+}
+
+// find walks down along str from root
+func (f *Frozen) find(str string) (uint32, bool) {
+	n := uint32(0)
+	for i := 0; i < len(str); {
+		c, size, ok := f.alphabet.next(str[i:])
+		if !ok {
+			return 0, false
+		}
+		if n, ok = f.child(n, c); !ok {
+			return 0, false
+		}
+		i += size
+	}
+	return n, true
+}
+
+func (f *Frozen) Match(str string) bool {
+	if str == "" {
+		return false
+	}
+	n, ok := f.find(str)
+	if !ok {
+		return false
+	}
+	_, _, exists := f.node(n)
+	return exists
+}
+
+// MatchPartial returns words start with str in lexicographic order
+func (f *Frozen) MatchPartial(str string) (res []string) {
+	n, ok := f.find(str)
+	if !ok {
+		return
+	}
+	budget := f.count
+	f.walk(n, []byte(str), &budget, func(word []byte) {
+		res = append(res, string(word))
+	})
+	return
+}
+
+// walk visits at most budget nodes, children ranges of a corrupted image
+// may overlap, which would make the same nodes be visited again and again
+func (f *Frozen) walk(n uint32, prefix []byte, budget *uint32, yield func(word []byte)) {
+	if *budget == 0 {
+		return
+	}
+	*budget--
+	first, k, exists := f.node(n)
+	if exists {
+		yield(prefix)
+	}
+	for j := first; j < first+k; j++ {
+		f.walk(j, f.alphabet.append(prefix, f.label(j)), budget, yield)
+	}
+}
+
+// prefixes calls yield with byte length of every word that is a prefix of s
+func (f *Frozen) prefixes(s string, yield func(l int)) {
+	n := uint32(0)
+	for i := 0; i < len(s); {
+		c, size, ok := f.alphabet.next(s[i:])
+		if !ok {
+			return
+		}
+		if n, ok = f.child(n, c); !ok {
+			return
+		}
+		i += size
+		if _, _, exists := f.node(n); exists {
+			yield(i)
+		}
+	}
+}
+
+// LongestPrefixOf returns the longest word which is a prefix of s
+func (f *Frozen) LongestPrefixOf(s string) (prefix string, ok bool) {
+	f.prefixes(s, func(l int) {
+		prefix, ok = s[:l], true
+	})
+	return
+}
+
+// PrefixesOf returns all words which are prefix of s, shortest first
+func (f *Frozen) PrefixesOf(s string) (prefixes []string) {
+	f.prefixes(s, func(l int) {
+		prefixes = append(prefixes, s[:l])
+	})
+	return
+}
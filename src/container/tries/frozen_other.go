@@ -0,0 +1,15 @@
+//go:build !unix
+
+package tries
+
+import "os"
+
+// OpenFrozen reads the frozen image at path into memory, mmap is only
+// used on unix
+func OpenFrozen(path string) (*Frozen, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewFrozen(data)
+}
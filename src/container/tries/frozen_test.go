@@ -0,0 +1,138 @@
+package tries
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFrozen(t *testing.T) {
+	for _, c := range []struct {
+		a       Alphabet
+		letters []rune
+	}{
+		{LowerCase, []rune("abcd")},
+		{Unicode, []rune("aé中\U0001F600")},
+		{Binary, []rune{0, 'a', 0xff}},
+	} {
+		tr := NewTriesWith(c.a)
+		word := func() string {
+			w := randomWord(c.letters, 6)
+			if c.a == Binary {
+				b := []byte{}
+				for _, r := range w {
+					b = append(b, byte(r))
+				}
+				w = string(b)
+			}
+			return w
+		}
+		for i := 0; i < 300; i++ {
+			tr.Insert(word())
+		}
+
+		path := filepath.Join(t.TempDir(), "frozen")
+		if err := os.WriteFile(path, tr.Freeze(), 0644); err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		f, err := OpenFrozen(path)
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+
+		if f.Len() != len(tr.dump(nil, c.a)) {
+			t.Errorf("alphabet %d, expect len %d, really %d", c.a, len(tr.dump(nil, c.a)), f.Len())
+		}
+		if got := f.MatchPartial(""); !slices.Equal(got, tr.MatchPartial("")) {
+			t.Errorf("alphabet %d, words not expected", c.a)
+		}
+		for i := 0; i < 300; i++ {
+			s := word()
+			if f.Match(s) != tr.Match(s) {
+				t.Errorf("alphabet %d, Match %q not expected", c.a, s)
+			}
+			if got, expect := f.MatchPartial(s[:len(s)/2]), tr.MatchPartial(s[:len(s)/2]); !slices.Equal(got, expect) {
+				t.Errorf("alphabet %d, MatchPartial %q expect %v, really %v", c.a, s, expect, got)
+			}
+			if got, expect := f.PrefixesOf(s), tr.PrefixesOf(s); !slices.Equal(got, expect) {
+				t.Errorf("alphabet %d, PrefixesOf %q expect %v, really %v", c.a, s, expect, got)
+			}
+			p, ok := f.LongestPrefixOf(s)
+			if ep, eok := tr.LongestPrefixOf(s); p != ep || ok != eok {
+				t.Errorf("alphabet %d, LongestPrefixOf %q expect %q, really %q", c.a, s, ep, p)
+			}
+		}
+
+		if err := f.Close(); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+	}
+}
+
+func TestFrozenEmptyAndCorrupted(t *testing.T) {
+	f, err := NewFrozen(NewTries().Freeze())
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if f.Match("a") || len(f.MatchPartial("")) != 0 || f.Len() != 0 {
+		t.Error("empty frozen trie expected")
+	}
+
+	tr := NewTries()
+	for _, word := range []string{"hello", "help", "world", "a", "ab"} {
+		tr.Insert(word)
+	}
+	data := tr.Freeze()
+	if _, err := NewFrozen(data[:len(data)-1]); err == nil {
+		t.Error("expect error for truncated image")
+	}
+	if _, err := NewFrozen(append([]byte("XXXX"), data[4:]...)); err == nil {
+		t.Error("expect error for bad magic")
+	}
+	if _, err := OpenFrozen(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expect error for missing file")
+	}
+
+	// corrupted nodes never panic
+	for i := frozenHeader; i < len(data); i++ {
+		corrupted := slices.Clone(data)
+		corrupted[i] ^= 0xff
+		f, err := NewFrozen(corrupted)
+		if err != nil {
+			t.Fatalf("unexpected error %s", err.Error())
+		}
+		f.MatchPartial("")
+		f.Match("help")
+		f.PrefixesOf("helpers")
+	}
+}
+
+func TestFrozenOverlapped(t *testing.T) {
+	// node i has children i+1 and i+2, so paths down double every two
+	// levels, and nodes 1 and 2 have children of each other
+	const count = 60
+	data := append([]byte(frozenMagic), frozenVersion, byte(LowerCase), 0, 0)
+	data = binary.LittleEndian.AppendUint32(data, count)
+	data = binary.LittleEndian.AppendUint32(data, count)
+	for i := uint32(0); i < count; i++ {
+		first, k := i+1, uint32(min(2, count-1-int(i)))
+		if i == 2 {
+			first = 1
+		}
+		data = binary.LittleEndian.AppendUint32(data, first)
+		data = binary.LittleEndian.AppendUint32(data, k<<1|1)
+	}
+	for i := 0; i < count; i++ {
+		data = binary.LittleEndian.AppendUint32(data, 'a')
+	}
+
+	f, err := NewFrozen(data)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err.Error())
+	}
+	if res := f.MatchPartial(""); len(res) > count {
+		t.Errorf("expect at most %d words, really %d", count, len(res))
+	}
+}
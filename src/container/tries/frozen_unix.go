@@ -0,0 +1,38 @@
+//go:build unix
+
+package tries
+
+import (
+	"os"
+	"syscall"
+)
+
+// OpenFrozen maps the frozen image at path read-only, pages are loaded on
+// demand and shared with other processes mapping the same file
+func OpenFrozen(path string) (*Frozen, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return NewFrozen(nil)
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := NewFrozen(data)
+	if err != nil {
+		syscall.Munmap(data)
+		return nil, err
+	}
+	f.close = func() error { return syscall.Munmap(data) }
+	return f, nil
+}
@@ -0,0 +1,135 @@
+package tries
+
+import (
+	"slices"
+)
+
+type FuzzyResult struct {
+	Word string
+	Dist int
+}
+
+// symbols splits s for edit distance, symbols not allowed by the alphabet
+// are kept, they just never match
+func (a Alphabet) symbols(s string) []rune {
+	if a != Binary {
+		return []rune(s)
+	}
+	rs := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		rs[i] = rune(s[i])
+	}
+	return rs
+}
+
+// fuzzy walks the trie computing Levenshtein distance against query one DP
+// row per level, subtrees whose row minimum exceeds max are skipped
+type fuzzy[T any] struct {
+	query  []rune
+	max    int
+	prefix bool
+	a      Alphabet
+	rows   [][]int // rows[d] is reused for every node at depth d
+	word   []byte
+	res    []FuzzyResult
+	// visited counts nodes whose DP row is computed
+	visited int
+}
+
+func (f *fuzzy[T]) row(d int) []int {
+	for len(f.rows) <= d {
+		f.rows = append(f.rows, make([]int, len(f.query)+1))
+	}
+	return f.rows[d]
+}
+
+// best is min distance between query and prefixes of the path so far,
+// only used for prefix matching
+func (f *fuzzy[T]) walk(n *node[T], d int, best int) {
+	prev := f.row(d)
+	m := len(f.query)
+	for i, c := range n.children {
+		if c == nil {
+			continue
+		}
+		f.visited++
+		sym := n.label(i)
+		cur := f.row(d + 1)
+		cur[0] = prev[0] + 1
+		low := cur[0]
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if f.query[j-1] == sym {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			low = min(low, cur[j])
+		}
+
+		l := len(f.word)
+		f.word = f.a.append(f.word, sym)
+		if !f.prefix {
+			if c.exists && cur[m] <= f.max {
+				f.res = append(f.res, FuzzyResult{string(f.word), cur[m]})
+			}
+			if low <= f.max {
+				f.walk(c, d+1, best)
+			}
+		} else {
+			b := min(best, cur[m])
+			if low >= b {
+				// going deeper never gets closer
+				if b <= f.max {
+					c.walk(f.word, f.a, func(word []byte, _ *node[T]) bool {
+						f.res = append(f.res, FuzzyResult{string(word), b})
+						return true
+					})
+				}
+			} else {
+				if c.exists && b <= f.max {
+					f.res = append(f.res, FuzzyResult{string(f.word), b})
+				}
+				// b > low here, so words below are at least low away
+				if low <= f.max {
+					f.walk(c, d+1, b)
+				}
+			}
+		}
+		f.word = f.word[:l]
+	}
+}
+
+func (n *node[T]) fuzzy(query string, max int, prefix bool, a Alphabet) []FuzzyResult {
+	if max < 0 {
+		return nil
+	}
+	f := &fuzzy[T]{query: a.symbols(query), max: max, prefix: prefix, a: a}
+	return f.run(n)
+}
+
+func (f *fuzzy[T]) run(n *node[T]) []FuzzyResult {
+	first := f.row(0)
+	for j := range first {
+		first[j] = j
+	}
+	// distance of the empty prefix
+	f.walk(n, 0, len(f.query))
+
+	// walk gives lexicographic order, keep it for same distance
+	slices.SortStableFunc(f.res, func(x, y FuzzyResult) int {
+		return x.Dist - y.Dist
+	})
+	return f.res
+}
+
+// FuzzyMatch returns words within Levenshtein distance maxDist of query,
+// ranked by distance then lexicographic order
+func (t *Tries) FuzzyMatch(query string, maxDist int) []FuzzyResult {
+	return t.fuzzy(query, maxDist, false, t.alphabet)
+}
+
+// FuzzyMatchPartial returns words having a prefix within Levenshtein
+// distance maxDist of query, Dist is the distance of the closest prefix
+func (t *Tries) FuzzyMatchPartial(query string, maxDist int) []FuzzyResult {
+	return t.fuzzy(query, maxDist, true, t.alphabet)
+}
@@ -0,0 +1,130 @@
+package tries
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tree := NewTries()
+	for _, word := range []string{"hello", "help", "hell", "shell", "world", "word", "held"} {
+		tree.Insert(word)
+	}
+
+	res := tree.FuzzyMatch("helo", 1)
+	if !slices.Equal(res, []FuzzyResult{{"held", 1}, {"hell", 1}, {"hello", 1}, {"help", 1}}) {
+		t.Errorf("not expected: %v", res)
+	}
+
+	res = tree.FuzzyMatch("hell", 1)
+	if len(res) == 0 || res[0] != (FuzzyResult{"hell", 0}) {
+		t.Errorf("exact match should rank first: %v", res)
+	}
+
+	if res := tree.FuzzyMatch("xyz", 1); len(res) != 0 {
+		t.Errorf("not expected: %v", res)
+	}
+	if res := tree.FuzzyMatch("hell", -1); len(res) != 0 {
+		t.Errorf("not expected: %v", res)
+	}
+}
+
+func TestFuzzyMatchRandom(t *testing.T) {
+	letters := []rune("abcdé日")
+	tree := NewTriesWith(Unicode)
+	var words []string
+	for i := 0; i < 300; i++ {
+		word := randomWord(letters, 7)
+		if !tree.Match(word) {
+			tree.Insert(word)
+			words = append(words, word)
+		}
+	}
+	slices.Sort(words)
+
+	for i := 0; i < 50; i++ {
+		query := randomWord(letters, 6)
+		max := rand.Intn(3)
+
+		var expect []FuzzyResult
+		var expectPartial []FuzzyResult
+		for _, word := range words {
+			rw := []rune(word)
+			if d := levenshtein([]rune(query), rw); d <= max {
+				expect = append(expect, FuzzyResult{word, d})
+			}
+			best := len([]rune(query))
+			for l := 1; l <= len(rw); l++ {
+				best = min(best, levenshtein([]rune(query), rw[:l]))
+			}
+			if best <= max {
+				expectPartial = append(expectPartial, FuzzyResult{word, best})
+			}
+		}
+		byDist := func(x, y FuzzyResult) int { return x.Dist - y.Dist }
+		slices.SortStableFunc(expect, byDist)
+		slices.SortStableFunc(expectPartial, byDist)
+
+		if res := tree.FuzzyMatch(query, max); !slices.Equal(res, expect) {
+			t.Errorf("fuzzy %s %d expect %v, really %v", query, max, expect, res)
+		}
+		if res := tree.FuzzyMatchPartial(query, max); !slices.Equal(res, expectPartial) {
+			t.Errorf("fuzzy partial %s %d expect %v, really %v", query, max, expectPartial, res)
+		}
+	}
+}
+
+func TestFuzzyMatchPartial(t *testing.T) {
+	tree := NewTries()
+	for _, word := range []string{"apple", "application", "apply", "banana", "appetite"} {
+		tree.Insert(word)
+	}
+
+	res := tree.FuzzyMatchPartial("aplic", 1)
+	if !slices.Equal(res, []FuzzyResult{{"application", 1}}) {
+		t.Errorf("not expected: %v", res)
+	}
+
+	res = tree.FuzzyMatchPartial("app", 0)
+	if len(res) != 4 || res[0].Word != "appetite" {
+		t.Errorf("not expected: %v", res)
+	}
+}
+
+func TestFuzzyMatchPartialPrune(t *testing.T) {
+	tree := NewTries()
+	for _, word := range dictionary(20000) {
+		tree.Insert(word)
+	}
+	total := countNodes(&tree.Node)
+
+	for _, query := range []string{"zzzzzzzz", "qqqqqqqqqqqq"} {
+		f := &fuzzy[struct{}]{query: LowerCase.symbols(query), max: 1, prefix: true, a: LowerCase}
+		f.run(&tree.Node)
+		// only paths within distance 1 of a prefix of query are walked
+		if f.visited*20 > total {
+			t.Errorf("%s visited %d of %d nodes", query, f.visited, total)
+		}
+	}
+}
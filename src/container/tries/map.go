@@ -0,0 +1,103 @@
+package tries
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// Map is a trie keyed by string, keys are visited in the same order
+// MatchPartial gives
+type Map[T any] struct {
+	node[T]
+	size     int
+	alphabet Alphabet
+}
+
+func NewMap[T any]() *Map[T] {
+	return NewMapWith[T](LowerCase)
+}
+
+func NewMapWith[T any](a Alphabet) *Map[T] {
+	return &Map[T]{alphabet: a}
+}
+
+func (m *Map[T]) Len() int {
+	return m.size
+}
+
+// Put sets value of key, replaces the old one if exists
+func (m *Map[T]) Put(key string, value T) error {
+	if key == "" {
+		return errors.New("empty key")
+	}
+
+	if !m.alphabet.valid(key) {
+		return fmt.Errorf("%s has chars not allowed", key)
+	}
+
+	n, _ := m.insert(key, m.alphabet)
+	if !n.exists {
+		n.exists = true
+		m.size += 1
+	}
+	n.value = value
+	return nil
+}
+
+func (m *Map[T]) Get(key string) (value T, ok bool) {
+	if key == "" {
+		return
+	}
+
+	if n := m.find(key, m.alphabet); n != nil && n.exists {
+		return n.value, true
+	}
+	return
+}
+
+// Delete removes key, reports whether it existed
+func (m *Map[T]) Delete(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	n := m.remove(key, m.alphabet)
+	if n == nil {
+		return false
+	}
+
+	var zero T
+	n.value = zero
+	m.size -= 1
+	return true
+}
+
+// LongestPrefixOf returns the longest key which is a prefix of s and its value
+func (m *Map[T]) LongestPrefixOf(s string) (key string, value T, ok bool) {
+	m.prefixes(s, m.alphabet, func(l int, n *node[T]) bool {
+		key, value, ok = s[:l], n.value, true
+		return true
+	})
+	return
+}
+
+// All yields every key and value in lexicographic order
+func (m *Map[T]) All() iter.Seq2[string, T] {
+	return m.Prefix("")
+}
+
+// Prefix yields keys starting with prefix and their values in
+// lexicographic order, the map must not be modified during iteration
+func (m *Map[T]) Prefix(prefix string) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		n := m.find(prefix, m.alphabet)
+		if n == nil {
+			return
+		}
+
+		n.walk([]byte(prefix), m.alphabet, func(key []byte, n *node[T]) bool {
+			return yield(string(key), n.value)
+		})
+	}
+}
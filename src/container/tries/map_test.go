@@ -0,0 +1,129 @@
+package tries
+
+import (
+	"testing"
+)
+
+func TestMapPutGet(t *testing.T) {
+	m := NewMap[int]()
+	words := []string{"a", "ab", "abc", "abd", "abe", "hello", "world"}
+	for i, word := range words {
+		if err := m.Put(word, i); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+	}
+	m.Put("hello", 100)
+
+	if err := m.Put("", 0); err == nil {
+		t.Error("expect error for empty key")
+	}
+	if err := m.Put("Hello", 0); err == nil {
+		t.Error("expect error for not allowed chars")
+	}
+
+	if m.Len() != len(words) {
+		t.Errorf("expect len %d, really %d", len(words), m.Len())
+	}
+
+	if v, ok := m.Get("abd"); !ok || v != 3 {
+		t.Errorf("expect abd => 3, really %d %v", v, ok)
+	}
+	if v, ok := m.Get("hello"); !ok || v != 100 {
+		t.Errorf("expect hello => 100, really %d %v", v, ok)
+	}
+	for _, key := range []string{"", "worl", "worlde", "Hello"} {
+		if _, ok := m.Get(key); ok {
+			t.Errorf("unexpected get %s", key)
+		}
+	}
+}
+
+func TestMapDelete(t *testing.T) {
+	m := NewMapWith[string](Unicode)
+	words := []string{"日本", "日本語", "中文", "abc"}
+	for _, word := range words {
+		m.Put(word, word)
+	}
+
+	if !m.Delete("日本") {
+		t.Error("expect delete 日本")
+	}
+	if m.Delete("日本") || m.Delete("日") || m.Delete("") {
+		t.Error("unexpected delete")
+	}
+	if _, ok := m.Get("日本"); ok {
+		t.Error("日本 should be deleted")
+	}
+	if v, ok := m.Get("日本語"); !ok || v != "日本語" {
+		t.Error("日本語 should be kept")
+	}
+
+	m.Delete("日本語")
+	if m.child('日') != nil {
+		t.Error("empty branch should be pruned")
+	}
+	m.Delete("abc")
+	m.Delete("中文")
+	if m.Len() != 0 || !m.empty() {
+		t.Errorf("map should be empty, len %d", m.Len())
+	}
+}
+
+func TestMapPrefix(t *testing.T) {
+	tree := NewTries()
+	m := NewMap[int]()
+	words := []string{"hello", "world", "abe", "a", "abd", "ab", "abc"}
+	for i, word := range words {
+		tree.Insert(word)
+		m.Put(word, i)
+	}
+
+	for _, prefix := range []string{"", "ab", "abc", "x", "W"} {
+		expect := tree.MatchPartial(prefix)
+		var keys []string
+		for k, v := range m.Prefix(prefix) {
+			if words[v] != k {
+				t.Errorf("value of %s not match, %d", k, v)
+			}
+			keys = append(keys, k)
+		}
+		if len(keys) != len(expect) {
+			t.Errorf("prefix %s expect %v, really %v", prefix, expect, keys)
+			continue
+		}
+		for i := range keys {
+			if keys[i] != expect[i] {
+				t.Errorf("prefix %s expect %v, really %v", prefix, expect, keys)
+				break
+			}
+		}
+	}
+
+	n := 0
+	for range m.All() {
+		n++
+		if n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Errorf("break not honored, %d", n)
+	}
+}
+
+func TestMapLongestPrefixOf(t *testing.T) {
+	m := NewMapWith[string](Binary)
+	m.Put("/api", "api")
+	m.Put("/api/v1", "v1")
+	m.Put("/static", "static")
+
+	if k, v, ok := m.LongestPrefixOf("/api/v1/users"); !ok || k != "/api/v1" || v != "v1" {
+		t.Errorf("expect /api/v1, really %s %s", k, v)
+	}
+	if k, v, ok := m.LongestPrefixOf("/api/v2"); !ok || k != "/api" || v != "api" {
+		t.Errorf("expect /api, really %s %s", k, v)
+	}
+	if _, _, ok := m.LongestPrefixOf("/ap"); ok {
+		t.Error("unexpected prefix of /ap")
+	}
+}
@@ -0,0 +1,178 @@
+package tries
+
+import (
+	"errors"
+	"fmt"
+)
+
+// pattern syntax:
+//
+//	?   matches exactly one symbol of the alphabet
+//	*   matches zero or more symbols
+//	\c  matches c literally, c can be ?, * or \
+//
+// any other char matches itself, and must be allowed by the alphabet
+
+const (
+	tokLiteral = iota
+	tokAny
+	tokStar
+)
+
+type token struct {
+	kind int
+	sym  rune
+}
+
+func (a Alphabet) compile(pattern string) (tokens []token, err error) {
+	for i := 0; i < len(pattern); {
+		tok := token{kind: tokLiteral}
+		switch pattern[i] {
+		case '?':
+			tok.kind = tokAny
+			i++
+		case '*':
+			tok.kind = tokStar
+			i++
+		case '\\':
+			if i+1 == len(pattern) {
+				return nil, errors.New("trailing \\ in pattern")
+			}
+			i++
+			fallthrough
+		default:
+			c, size, ok := a.next(pattern[i:])
+			if !ok {
+				return nil, fmt.Errorf("char not allowed at %d of pattern %s", i, pattern)
+			}
+			tok.sym = c
+			i += size
+		}
+
+		if tok.kind == tokStar && len(tokens) > 0 && tokens[len(tokens)-1].kind == tokStar {
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	return
+}
+
+// matcher runs the pattern as an NFA down the trie, a state is the set of
+// token positions reached, position len(tokens) means accepted
+type matcher[T any] struct {
+	tokens []token
+	a      Alphabet
+	sets   [][]uint64 // sets[d] is reused for every node at depth d
+	word   []byte
+	res    []string
+}
+
+func (m *matcher[T]) set(d int) []uint64 {
+	for len(m.sets) <= d {
+		m.sets = append(m.sets, make([]uint64, (len(m.tokens)+64)/64))
+	}
+	s := m.sets[d]
+	clear(s)
+	return s
+}
+
+func has(s []uint64, i int) bool { return s[i/64]&(1<<(i%64)) != 0 }
+func add(s []uint64, i int)      { s[i/64] |= 1 << (i % 64) }
+
+// closure lets every reached star match nothing
+func (m *matcher[T]) closure(s []uint64) {
+	for i, tok := range m.tokens {
+		if tok.kind == tokStar && has(s, i) {
+			add(s, i+1)
+		}
+	}
+}
+
+// step computes positions reached from cur after consuming c, reports
+// whether any is reached
+func (m *matcher[T]) step(cur, next []uint64, c rune) (alive bool) {
+	for i, tok := range m.tokens {
+		if !has(cur, i) {
+			continue
+		}
+		switch tok.kind {
+		case tokStar:
+			add(next, i)
+		case tokAny:
+			add(next, i+1)
+		default:
+			if tok.sym != c {
+				continue
+			}
+			add(next, i+1)
+		}
+		alive = true
+	}
+	m.closure(next)
+	return
+}
+
+// literal returns the only symbol can be consumed from cur, if any
+func (m *matcher[T]) literal(cur []uint64) (c rune, ok bool) {
+	for i, tok := range m.tokens {
+		if has(cur, i) {
+			if tok.kind != tokLiteral || ok {
+				return 0, false
+			}
+			c, ok = tok.sym, true
+		}
+	}
+	return
+}
+
+func (m *matcher[T]) walk(n *node[T], d int) {
+	cur := m.sets[d]
+	if n.exists && has(cur, len(m.tokens)) {
+		m.res = append(m.res, string(m.word))
+	}
+
+	if c, ok := m.literal(cur); ok {
+		// fast path, no need to try every child
+		if child := n.child(c); child != nil {
+			m.descend(child, d, c)
+		}
+		return
+	}
+
+	for i, child := range n.children {
+		if child != nil {
+			m.descend(child, d, n.label(i))
+		}
+	}
+}
+
+func (m *matcher[T]) descend(child *node[T], d int, c rune) {
+	next := m.set(d + 1)
+	if !m.step(m.sets[d], next, c) {
+		return
+	}
+	l := len(m.word)
+	m.word = m.a.append(m.word, c)
+	m.walk(child, d+1)
+	m.word = m.word[:l]
+}
+
+func (n *node[T]) matchPattern(pattern string, a Alphabet) ([]string, error) {
+	tokens, err := a.compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &matcher[T]{tokens: tokens, a: a}
+	start := m.set(0)
+	add(start, 0)
+	m.closure(start)
+	m.walk(n, 0)
+	return m.res, nil
+}
+
+// MatchPattern returns words match pattern in lexicographic order, see
+// above for the syntax, an error is returned for malformed pattern
+func (t *Tries) MatchPattern(pattern string) ([]string, error) {
+	return t.matchPattern(pattern, t.alphabet)
+}
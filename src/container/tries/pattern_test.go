@@ -0,0 +1,121 @@
+package tries
+
+import (
+	"path"
+	"slices"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tree := NewTries()
+	words := []string{"hello", "hallo", "hullo", "help", "hell", "sing", "singing", "ring", "ab", "abc", "abcd"}
+	for _, word := range words {
+		tree.Insert(word)
+	}
+
+	cases := []struct {
+		pattern string
+		expect  []string
+	}{
+		{"h?llo", []string{"hallo", "hello", "hullo"}},
+		{"ab*", []string{"ab", "abc", "abcd"}},
+		{"*ing", []string{"ring", "sing", "singing"}},
+		{"*", []string{"ab", "abc", "abcd", "hallo", "hell", "hello", "help", "hullo", "ring", "sing", "singing"}},
+		{"?", nil},
+		{"????", []string{"abcd", "hell", "help", "ring", "sing"}},
+		{"h*l*", []string{"hallo", "hell", "hello", "help", "hullo"}},
+		{"*in*", []string{"ring", "sing", "singing"}},
+		{"abc", []string{"abc"}},
+		{"abx*", nil},
+		{"", nil},
+	}
+	for _, c := range cases {
+		res, err := tree.MatchPattern(c.pattern)
+		if err != nil {
+			t.Errorf("unexpected error for %s, %s", c.pattern, err.Error())
+		}
+		if !slices.Equal(res, c.expect) {
+			t.Errorf("pattern %s expect %v, really %v", c.pattern, c.expect, res)
+		}
+	}
+
+	for _, pattern := range []string{"ab\\", "Hello", "a?B*"} {
+		if _, err := tree.MatchPattern(pattern); err == nil {
+			t.Errorf("expect error for pattern %s", pattern)
+		}
+	}
+}
+
+func TestMatchPatternEscape(t *testing.T) {
+	tree := NewTriesWith(Binary)
+	for _, word := range []string{"a*b", "axb", "a?", "a\\"} {
+		tree.Insert(word)
+	}
+
+	if res, _ := tree.MatchPattern("a\\*b"); !slices.Equal(res, []string{"a*b"}) {
+		t.Errorf("not expected: %v", res)
+	}
+	if res, _ := tree.MatchPattern("a*b"); !slices.Equal(res, []string{"a*b", "axb"}) {
+		t.Errorf("not expected: %v", res)
+	}
+	if res, _ := tree.MatchPattern("a\\?"); !slices.Equal(res, []string{"a?"}) {
+		t.Errorf("not expected: %v", res)
+	}
+	if res, _ := tree.MatchPattern("a\\\\"); !slices.Equal(res, []string{"a\\"}) {
+		t.Errorf("not expected: %v", res)
+	}
+}
+
+func TestMatchPatternAlphabet(t *testing.T) {
+	for _, a := range []Alphabet{Unicode, Binary} {
+		tree := NewTriesWith(a)
+		for _, word := range []string{"日本", "日x", "日本語"} {
+			tree.Insert(word)
+		}
+
+		res, err := tree.MatchPattern("日?")
+		if err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+		// ? is a rune for unicode and a byte for binary
+		expect := []string{"日x", "日本"}
+		if a == Binary {
+			expect = []string{"日x"}
+		}
+		if !slices.Equal(res, expect) {
+			t.Errorf("alphabet %d expect %v, really %v", a, expect, res)
+		}
+	}
+
+	if _, err := NewTriesWith(Unicode).MatchPattern("a\xff"); err == nil {
+		t.Error("expect error for invalid utf-8")
+	}
+}
+
+func TestMatchPatternRandom(t *testing.T) {
+	letters := []rune("abc")
+	tree := NewTries()
+	var words []string
+	for i := 0; i < 300; i++ {
+		word := randomWord(letters, 6)
+		if !tree.Match(word) {
+			tree.Insert(word)
+			words = append(words, word)
+		}
+	}
+	slices.Sort(words)
+
+	for i := 0; i < 200; i++ {
+		pattern := randomWord([]rune("abc?*"), 5)
+		var expect []string
+		for _, word := range words {
+			// same syntax as path.Match without brackets for these patterns
+			if ok, _ := path.Match(pattern, word); ok {
+				expect = append(expect, word)
+			}
+		}
+		if res, _ := tree.MatchPattern(pattern); !slices.Equal(res, expect) {
+			t.Errorf("pattern %s expect %v, really %v", pattern, expect, res)
+		}
+	}
+}
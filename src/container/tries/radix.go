@@ -0,0 +1,159 @@
+package tries
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// radixNode is reached by an edge labelled with label, edges are split on
+// bytes, so an edge of a Unicode radix may end in the middle of a rune
+type radixNode struct {
+	label    string
+	children []*radixNode // sorted by label[0]
+	exists   bool
+}
+
+// Radix is a compressed trie, chains of single child nodes are collapsed
+// into one edge, it gives same results as Tries for same words
+type Radix struct {
+	radixNode
+	size     int
+	alphabet Alphabet
+}
+
+func NewRadix() *Radix {
+	return NewRadixWith(LowerCase)
+}
+
+func NewRadixWith(a Alphabet) *Radix {
+	return &Radix{alphabet: a}
+}
+
+// NewRadixFromTries builds a Radix holds same words with t
+func NewRadixFromTries(t *Tries) *Radix {
+	r := NewRadixWith(t.alphabet)
+	t.walk(nil, t.alphabet, func(word []byte, _ *Node) bool {
+		r.insert(string(word))
+		return true
+	})
+	return r
+}
+
+func (r *Radix) Len() int {
+	return r.size
+}
+
+func (n *radixNode) search(b byte) (int, bool) {
+	return slices.BinarySearchFunc(n.children, b, func(c *radixNode, b byte) int {
+		return int(c.label[0]) - int(b)
+	})
+}
+
+func (r *Radix) Insert(word string) error {
+	if word == "" {
+		return errors.New("empty word")
+	}
+
+	if !r.alphabet.valid(word) {
+		return fmt.Errorf("%s has chars not allowed", word)
+	}
+
+	r.insert(word)
+	return nil
+}
+
+func (r *Radix) insert(word string) {
+	n := &r.radixNode
+	for word != "" {
+		i, found := n.search(word[0])
+		if !found {
+			n.children = slices.Insert(n.children, i, &radixNode{label: word, exists: true})
+			r.size += 1
+			return
+		}
+
+		c := n.children[i]
+		l := commonPrefix(c.label, word)
+		if l < len(c.label) {
+			// split c at l
+			mid := &radixNode{label: c.label[:l], children: []*radixNode{c}}
+			c.label = c.label[l:]
+			n.children[i] = mid
+			c = mid
+		}
+		n = c
+		word = word[l:]
+	}
+
+	if !n.exists {
+		n.exists = true
+		r.size += 1
+	}
+}
+
+func commonPrefix(a, b string) (l int) {
+	for l < len(a) && l < len(b) && a[l] == b[l] {
+		l++
+	}
+	return
+}
+
+// find walks down along str, the returned node may be reached in the middle
+// of its edge, rest is the part of the edge beyond str
+func (r *Radix) find(str string) (n *radixNode, rest string) {
+	n = &r.radixNode
+	for str != "" {
+		i, found := n.search(str[0])
+		if !found {
+			return nil, ""
+		}
+		n = n.children[i]
+		if len(str) < len(n.label) {
+			if !strings.HasPrefix(n.label, str) {
+				return nil, ""
+			}
+			return n, n.label[len(str):]
+		}
+		if !strings.HasPrefix(str, n.label) {
+			return nil, ""
+		}
+		str = str[len(n.label):]
+	}
+	return n, ""
+}
+
+func (r *Radix) Match(str string) bool {
+	if str == "" {
+		return false
+	}
+
+	n, rest := r.find(str)
+	return n != nil && rest == "" && n.exists
+}
+
+func (r *Radix) MatchPartial(str string) (res []string) {
+	if !r.alphabet.valid(str) {
+		return
+	}
+
+	n, rest := r.find(str)
+	if n == nil {
+		return
+	}
+
+	return n.dump(append([]byte(str), rest...))
+}
+
+func (n *radixNode) dump(prefix []byte) (words []string) {
+	if n.exists {
+		words = append(words, string(prefix))
+	}
+
+	for _, c := range n.children {
+		words = append(words, c.dump(append(prefix, c.label...))...)
+	}
+
+	return
+}
@@ -0,0 +1,111 @@
+package tries
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func countNodes(n *Node) (c int) {
+	c = 1
+	for _, child := range n.children {
+		if child != nil {
+			c += countNodes(child)
+		}
+	}
+	return
+}
+
+func countRadixNodes(n *radixNode) (c int) {
+	c = 1
+	for _, child := range n.children {
+		c += countRadixNodes(child)
+	}
+	return
+}
+
+func randomWord(letters []rune, max int) string {
+	word := make([]rune, 1+rand.Intn(max))
+	for i := range word {
+		word[i] = letters[rand.Intn(len(letters))]
+	}
+	return string(word)
+}
+
+func TestRadixMatch(t *testing.T) {
+	r := NewRadix()
+	words := []string{"a", "ab", "abc", "abd", "abe", "hello", "world", "worlds", "help"}
+	for _, word := range words {
+		if err := r.Insert(word); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+	}
+	r.Insert("hello")
+
+	if err := r.Insert("Hello"); err == nil {
+		t.Error("expect error for uppercase word")
+	}
+	if r.Len() != len(words) {
+		t.Errorf("expect len %d, really %d", len(words), r.Len())
+	}
+
+	for _, word := range words {
+		if !r.Match(word) {
+			t.Errorf("expect match %s", word)
+		}
+	}
+	for _, word := range []string{"", "hel", "worl", "worldss", "Hello", "abf"} {
+		if r.Match(word) {
+			t.Errorf("unexpected match %s", word)
+		}
+	}
+
+	if partial := r.MatchPartial("wo"); !slices.Equal(partial, []string{"world", "worlds"}) {
+		t.Errorf("not expected: %v", partial)
+	}
+	if partial := r.MatchPartial("hel"); !slices.Equal(partial, []string{"hello", "help"}) {
+		t.Errorf("not expected: %v", partial)
+	}
+	if partial := r.MatchPartial("hex"); len(partial) != 0 {
+		t.Errorf("not expected: %v", partial)
+	}
+}
+
+func TestRadixFromTries(t *testing.T) {
+	for _, a := range []Alphabet{LowerCase, Unicode} {
+		letters := []rune("abcdefghijklmnopqrstuvwxyz")
+		if a == Unicode {
+			letters = []rune("aéèêz日本")
+		}
+
+		tree := NewTriesWith(a)
+		for i := 0; i < 2000; i++ {
+			tree.Insert(randomWord(letters, 12))
+		}
+		r := NewRadixFromTries(tree)
+
+		all := tree.MatchPartial("")
+		if r.Len() != len(all) {
+			t.Errorf("expect len %d, really %d", len(all), r.Len())
+		}
+		if partial := r.MatchPartial(""); !slices.Equal(partial, all) {
+			t.Errorf("all words of radix differ from tries")
+		}
+
+		for i := 0; i < 500; i++ {
+			word := randomWord(letters, 6)
+			if r.Match(word) != tree.Match(word) {
+				t.Errorf("match %s differ", word)
+			}
+			if !slices.Equal(r.MatchPartial(word), tree.MatchPartial(word)) {
+				t.Errorf("partial match %s differ", word)
+			}
+		}
+
+		tn, rn := countNodes(&tree.Node), countRadixNodes(&r.radixNode)
+		if rn >= tn {
+			t.Errorf("radix should have less nodes, %d v.s. %d", rn, tn)
+		}
+		t.Logf("alphabet %d: tries nodes %d, radix nodes %d", a, tn, rn)
+	}
+}
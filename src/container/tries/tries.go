@@ -5,21 +5,136 @@ import (
 	//"flag"
 	"fmt"
 	"math"
+	"slices"
 	//"sort"
 	//"strings"
+	"unicode/utf8"
 )
 
 const RunWidth = 26
 
-type Node struct {
-	children [RunWidth]*Node
+// Alphabet decides how a word is split into symbols and how children are stored
+type Alphabet int
+
+const (
+	// 'a'..'z' only, children are kept in a dense array indexed by c-'a'
+	LowerCase Alphabet = iota
+	// any valid utf-8 rune, children are kept sparse and sorted
+	Unicode
+	// any byte, children are kept sparse and sorted
+	Binary
+)
+
+// next decodes the symbol at the beginning of s, which must not be empty
+func (a Alphabet) next(s string) (c rune, size int, ok bool) {
+	switch a {
+	case Binary:
+		return rune(s[0]), 1, true
+	case Unicode:
+		c, size = utf8.DecodeRuneInString(s)
+		return c, size, c != utf8.RuneError || size > 1
+	default:
+		c = rune(s[0])
+		return c, 1, 'a' <= c && c <= 'z'
+	}
+}
+
+func (a Alphabet) append(b []byte, c rune) []byte {
+	if a == Binary {
+		return append(b, byte(c))
+	}
+	return utf8.AppendRune(b, c)
+}
+
+func (a Alphabet) valid(word string) bool {
+	for i := 0; i < len(word); {
+		_, size, ok := a.next(word[i:])
+		if !ok {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+type node[T any] struct {
+	value T
+	// labels is nil for a dense node, whose children is indexed by c-'a',
+	// otherwise children is parallel to labels which is kept sorted
+	children []*node[T]
+	labels   []rune
 	exists   bool
 }
 
+type Node = node[struct{}]
+
+func (n *node[T]) child(c rune) *node[T] {
+	if n.labels == nil {
+		if c < 'a' || c > 'z' || len(n.children) == 0 {
+			return nil
+		}
+		return n.children[c-'a']
+	}
+	if i, found := slices.BinarySearch(n.labels, c); found {
+		return n.children[i]
+	}
+	return nil
+}
+
+// addChild returns child for c, creates it if not exists
+func (n *node[T]) addChild(c rune, dense bool) *node[T] {
+	if dense {
+		if n.children == nil {
+			n.children = make([]*node[T], RunWidth)
+		}
+		if n.children[c-'a'] == nil {
+			n.children[c-'a'] = &node[T]{}
+		}
+		return n.children[c-'a']
+	}
+
+	i, found := slices.BinarySearch(n.labels, c)
+	if !found {
+		n.labels = slices.Insert(n.labels, i, c)
+		n.children = slices.Insert(n.children, i, &node[T]{})
+	}
+	return n.children[i]
+}
+
+func (n *node[T]) removeChild(c rune) {
+	if n.labels == nil {
+		n.children[c-'a'] = nil
+		if !slices.ContainsFunc(n.children, func(c *node[T]) bool { return c != nil }) {
+			n.children = nil
+		}
+		return
+	}
+	if i, found := slices.BinarySearch(n.labels, c); found {
+		n.labels = slices.Delete(n.labels, i, i+1)
+		n.children = slices.Delete(n.children, i, i+1)
+	}
+}
+
+// empty means n holds no word and has no child
+func (n *node[T]) empty() bool {
+	return !n.exists && len(n.children) == 0
+}
+
+// label returns the symbol leads to children[i]
+func (n *node[T]) label(i int) rune {
+	if n.labels == nil {
+		return rune('a' + i)
+	}
+	return n.labels[i]
+}
+
 type Tries struct {
 	Node
-	hmin int
-	hmax int
+	hmin     int
+	hmax     int
+	alphabet Alphabet
+	// counts[h] is the count of words have h symbols
+	counts []int
 }
 
 func (t *Tries) Insert(word string) (err error) {
@@ -28,22 +143,21 @@ func (t *Tries) Insert(word string) (err error) {
 		return errors.New("empty word")
 	}
 
-	h := 0
-	cur := &t.Node
-	for _, c := range word {
-		if c < 'a' || c > 'z' {
-			return fmt.Errorf("%s has chars not allowed", word)
-		}
-
-		if cur.children[c-'a'] == nil {
-			cur.children[c-'a'] = &Node{}
-		}
-		cur = cur.children[c-'a']
+	if !t.alphabet.valid(word) {
+		return fmt.Errorf("%s has chars not allowed", word)
+	}
 
-		h += 1
+	cur, h := t.insert(word, t.alphabet)
+	if cur.exists {
+		return
 	}
 	cur.exists = true
 
+	for len(t.counts) <= h {
+		t.counts = append(t.counts, 0)
+	}
+	t.counts[h] += 1
+
 	if h < t.hmin {
 		t.hmin = h
 	}
@@ -55,35 +169,146 @@ func (t *Tries) Insert(word string) (err error) {
 	return
 }
 
-func (n *Node) dump(prefix string) (words []string) {
-	if n.exists {
-		words = append(words, prefix)
+// insert creates path for word which must be valid, h is count of symbols
+func (n *node[T]) insert(word string, a Alphabet) (leaf *node[T], h int) {
+	for i := 0; i < len(word); {
+		c, size, _ := a.next(word[i:])
+		n = n.addChild(c, a == LowerCase)
+		i += size
+
+		h += 1
 	}
+	return n, h
+}
 
-	for i, n := range n.children {
-		if n != nil {
-			words = append(words, n.dump(prefix+string(i+'a'))...)
+// walk yields existing words under n in lexicographic order, prefix is
+// reused across calls, yield should copy it if needed
+func (n *node[T]) walk(prefix []byte, a Alphabet, yield func(word []byte, n *node[T]) bool) bool {
+	if n.exists && !yield(prefix, n) {
+		return false
+	}
+
+	for i, c := range n.children {
+		if c != nil && !c.walk(a.append(prefix, n.label(i)), a, yield) {
+			return false
 		}
 	}
 
+	return true
+}
+
+func (n *node[T]) dump(prefix []byte, a Alphabet) (words []string) {
+	n.walk(prefix, a, func(word []byte, _ *node[T]) bool {
+		words = append(words, string(word))
+		return true
+	})
+
 	return
 }
 
-func (t *Tries) Match(str string) bool {
-	if str == "" {
-		return false
+// cut detaches the subtree at prefix and prunes branches left empty,
+// returns the detached subtree, nil if no such path
+func (n *node[T]) cut(prefix string, a Alphabet) (removed *node[T]) {
+	c, size, ok := a.next(prefix)
+	if !ok {
+		return nil
+	}
+	child := n.child(c)
+	if child == nil {
+		return nil
 	}
+	if rest := prefix[size:]; rest == "" {
+		removed = child
+	} else if removed = child.cut(rest, a); removed == nil || !child.empty() {
+		return
+	}
+	n.removeChild(c)
+	return
+}
 
-	n := &t.Node
-	for _, c := range str {
-		if n.children[c-'a'] != nil {
-			n = n.children[c-'a']
-		} else {
-			return false
+// remove clears word and prunes branches left empty, returns the node
+// held word, nil if word not exists
+func (n *node[T]) remove(word string, a Alphabet) (removed *node[T]) {
+	if word == "" {
+		if !n.exists {
+			return nil
+		}
+		n.exists = false
+		return n
+	}
+
+	c, size, ok := a.next(word)
+	if !ok {
+		return nil
+	}
+	child := n.child(c)
+	if child == nil {
+		return nil
+	}
+	if removed = child.remove(word[size:], a); removed != nil && child.empty() {
+		n.removeChild(c)
+	}
+	return
+}
+
+// find walks down along str, nil if no such path
+func (n *node[T]) find(str string, a Alphabet) *node[T] {
+	for i := 0; i < len(str); {
+		c, size, ok := a.next(str[i:])
+		if !ok {
+			return nil
+		}
+		if n = n.child(c); n == nil {
+			return nil
 		}
+		i += size
 	}
+	return n
+}
 
-	if n.exists {
+// prefixes calls yield with byte length of every word that is a prefix of s,
+// shortest first, till yield returns false
+func (n *node[T]) prefixes(s string, a Alphabet, yield func(l int, n *node[T]) bool) {
+	for i := 0; i < len(s); {
+		c, size, ok := a.next(s[i:])
+		if !ok {
+			return
+		}
+		if n = n.child(c); n == nil {
+			return
+		}
+		i += size
+		if n.exists && !yield(i, n) {
+			return
+		}
+	}
+}
+
+// LongestPrefixOf returns the longest word which is a prefix of s
+func (t *Tries) LongestPrefixOf(s string) (prefix string, ok bool) {
+	t.prefixes(s, t.alphabet, func(l int, _ *Node) bool {
+		prefix, ok = s[:l], true
+		return true
+	})
+	return
+}
+
+// PrefixesOf returns all words which are prefix of s, shortest first,
+// they are sub-strings of s
+func (t *Tries) PrefixesOf(s string) (prefixes []string) {
+	t.prefixes(s, t.alphabet, func(l int, _ *Node) bool {
+		prefixes = append(prefixes, s[:l])
+		return true
+	})
+	return
+}
+
+func (t *Tries) Match(str string) bool {
+	if str == "" {
+		return false
+	}
+
+	if n := t.find(str, t.alphabet); n != nil && n.exists {
 		return true
 	}
 
@@ -91,18 +316,73 @@ func (t *Tries) Match(str string) bool {
 }
 
 func (t *Tries) MatchPartial(str string) (res []string) {
-	n := &t.Node
-	for _, c := range str {
-		if n.children[c-'a'] != nil {
-			n = n.children[c-'a']
-		} else {
-			return
-		}
+	n := t.find(str, t.alphabet)
+	if n == nil {
+		return
+	}
+
+	return n.dump([]byte(str), t.alphabet)
+}
+
+// Delete removes word, reports whether it existed
+func (t *Tries) Delete(word string) bool {
+	if word == "" {
+		return false
+	}
+
+	if t.remove(word, t.alphabet) == nil {
+		return false
 	}
 
-	return n.dump(str)
+	t.counts[t.height(word)] -= 1
+	t.fixHeight()
+	return true
+}
+
+// DeletePrefix removes all words start with prefix, returns count of removed
+func (t *Tries) DeletePrefix(prefix string) (c int) {
+	var n *Node
+	if prefix == "" {
+		root := t.Node
+		n = &root
+		t.Node = Node{}
+	} else if n = t.cut(prefix, t.alphabet); n == nil {
+		return 0
+	}
+
+	h := t.height(prefix)
+	n.walk(nil, t.alphabet, func(word []byte, _ *Node) bool {
+		t.counts[h+t.height(string(word))] -= 1
+		c += 1
+		return true
+	})
+	t.fixHeight()
+	return
+}
+
+// height returns count of symbols of a valid word
+func (t *Tries) height(word string) int {
+	if t.alphabet == Binary {
+		return len(word)
+	}
+	return utf8.RuneCountInString(word)
+}
+
+// fixHeight recomputes hmin and hmax from counts
+func (t *Tries) fixHeight() {
+	t.hmin, t.hmax = math.MaxInt32, math.MinInt32
+	for h, c := range t.counts {
+		if c > 0 {
+			t.hmin = min(t.hmin, h)
+			t.hmax = max(t.hmax, h)
+		}
+	}
 }
 
 func NewTries() *Tries {
-	return &Tries{hmin: math.MaxInt32, hmax: math.MinInt32}
+	return NewTriesWith(LowerCase)
+}
+
+func NewTriesWith(a Alphabet) *Tries {
+	return &Tries{hmin: math.MaxInt32, hmax: math.MinInt32, alphabet: a}
 }
@@ -44,3 +44,228 @@ func TestMatchPartial(t *testing.T) {
 		t.Errorf("expect %d match, found %d", len(words), len(partial))
 	}
 }
+
+func TestMatchNotAllowed(t *testing.T) {
+	tree := NewTries()
+	tree.Insert("hello")
+
+	if err := tree.Insert("Hello"); err == nil {
+		t.Error("expect error for uppercase word")
+	}
+
+	for _, word := range []string{"Hello", "hé", "HELLO", "hello!"} {
+		if tree.Match(word) {
+			t.Errorf("unexpected match %s", word)
+		}
+		if partial := tree.MatchPartial(word); len(partial) != 0 {
+			t.Errorf("unexpected partial match %s: %v", word, partial)
+		}
+	}
+}
+
+func TestUnicode(t *testing.T) {
+	tree := NewTriesWith(Unicode)
+	words := []string{"Straße", "Strasse", "straße", "日本", "日本語", "中文", "a", "Zürich"}
+	for _, word := range words {
+		if err := tree.Insert(word); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+	}
+
+	if err := tree.Insert("bad\xffutf8"); err == nil {
+		t.Error("expect error for invalid utf-8")
+	}
+
+	for _, word := range words {
+		if !tree.Match(word) {
+			t.Errorf("expect match %s", word)
+		}
+	}
+	if tree.Match("日") || tree.Match("Straß") {
+		t.Error("unexpected match of prefix")
+	}
+
+	partial := tree.MatchPartial("日本")
+	if len(partial) != 2 || partial[0] != "日本" || partial[1] != "日本語" {
+		t.Errorf("not expected: %v", partial)
+	}
+
+	partial = tree.MatchPartial("")
+	expect := []string{"Strasse", "Straße", "Zürich", "a", "straße", "中文", "日本", "日本語"}
+	if len(partial) != len(expect) {
+		t.Fatalf("expect %d match, found %d", len(expect), len(partial))
+	}
+	for i := range expect {
+		if partial[i] != expect[i] {
+			t.Errorf("not expected order: %v", partial)
+			break
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	tree := NewTriesWith(Binary)
+	words := []string{"\x00\x01", "\x00", "\xff\xfe", "ab"}
+	for _, word := range words {
+		if err := tree.Insert(word); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+	}
+
+	for _, word := range words {
+		if !tree.Match(word) {
+			t.Errorf("expect match %q", word)
+		}
+	}
+
+	partial := tree.MatchPartial("")
+	expect := []string{"\x00", "\x00\x01", "ab", "\xff\xfe"}
+	if len(partial) != len(expect) {
+		t.Fatalf("expect %d match, found %d", len(expect), len(partial))
+	}
+	for i := range expect {
+		if partial[i] != expect[i] {
+			t.Errorf("not expected order: %q", partial)
+			break
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tree := NewTries()
+	words := []string{"a", "ab", "abc", "abd", "abe", "hello", "world"}
+	for _, word := range words {
+		tree.Insert(word)
+	}
+
+	if tree.Delete("abcd") || tree.Delete("") || tree.Delete("wor") || tree.Delete("Hello") {
+		t.Error("unexpected delete of nonexist word")
+	}
+
+	if !tree.Delete("hello") || !tree.Delete("world") {
+		t.Error("expect delete hello and world")
+	}
+	if tree.Match("hello") || tree.Delete("hello") {
+		t.Error("hello should be gone")
+	}
+	if tree.child('h') != nil || tree.child('w') != nil {
+		t.Error("empty branch should be pruned")
+	}
+	if tree.hmax != 3 || tree.hmin != 1 {
+		t.Errorf("height expect [1, 3], really [%d, %d]", tree.hmin, tree.hmax)
+	}
+
+	if !tree.Delete("ab") {
+		t.Error("expect delete ab")
+	}
+	if !tree.Match("abc") || tree.Match("ab") {
+		t.Error("delete ab should keep abc")
+	}
+
+	if !tree.Delete("a") || tree.hmin != 3 {
+		t.Errorf("hmin expect 3, really %d", tree.hmin)
+	}
+
+	partial := tree.MatchPartial("")
+	if len(partial) != 3 || partial[0] != "abc" || partial[2] != "abe" {
+		t.Errorf("not expected: %v", partial)
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	tree := NewTriesWith(Unicode)
+	words := []string{"a", "ab", "abc", "abd", "abe", "hello", "日本", "日本語"}
+	for _, word := range words {
+		tree.Insert(word)
+	}
+
+	if c := tree.DeletePrefix("x"); c != 0 {
+		t.Errorf("expect nothing removed, really %d", c)
+	}
+
+	if c := tree.DeletePrefix("ab"); c != 4 {
+		t.Errorf("expect 4 removed, really %d", c)
+	}
+	if !tree.Match("a") || tree.Match("abc") {
+		t.Error("delete prefix ab should keep a only")
+	}
+
+	if c := tree.DeletePrefix("日"); c != 2 {
+		t.Errorf("expect 2 removed, really %d", c)
+	}
+	if tree.child('日') != nil {
+		t.Error("empty branch should be pruned")
+	}
+	if tree.hmin != 1 || tree.hmax != 5 {
+		t.Errorf("height expect [1, 5], really [%d, %d]", tree.hmin, tree.hmax)
+	}
+
+	if c := tree.DeletePrefix(""); c != 2 {
+		t.Errorf("expect 2 removed, really %d", c)
+	}
+	if len(tree.MatchPartial("")) != 0 || !tree.empty() {
+		t.Error("tree should be empty")
+	}
+	if tree.hmin != NewTries().hmin || tree.hmax != NewTries().hmax {
+		t.Errorf("height should be reset, really [%d, %d]", tree.hmin, tree.hmax)
+	}
+
+	tree.Insert("日本")
+	if !tree.Match("日本") || tree.hmax != 2 {
+		t.Error("insert after delete all failed")
+	}
+}
+
+func TestPrefixesOf(t *testing.T) {
+	tree := NewTries()
+	for _, word := range []string{"a", "ab", "abc", "abd", "abcde", "hello"} {
+		tree.Insert(word)
+	}
+
+	cases := []struct {
+		s        string
+		longest  string
+		prefixes []string
+	}{
+		{"abcdef", "abcde", []string{"a", "ab", "abc", "abcde"}},
+		{"abd", "abd", []string{"a", "ab", "abd"}},
+		{"abx", "ab", []string{"a", "ab"}},
+		{"hell", "", nil},
+		{"hello/world", "hello", []string{"hello"}},
+		{"", "", nil},
+		{"xyz", "", nil},
+	}
+
+	for _, c := range cases {
+		longest, ok := tree.LongestPrefixOf(c.s)
+		if longest != c.longest || ok != (c.longest != "") {
+			t.Errorf("longest prefix of %s expect %s, really %s", c.s, c.longest, longest)
+		}
+
+		prefixes := tree.PrefixesOf(c.s)
+		if len(prefixes) != len(c.prefixes) {
+			t.Errorf("prefixes of %s expect %v, really %v", c.s, c.prefixes, prefixes)
+			continue
+		}
+		for i := range prefixes {
+			if prefixes[i] != c.prefixes[i] {
+				t.Errorf("prefixes of %s expect %v, really %v", c.s, c.prefixes, prefixes)
+				break
+			}
+		}
+	}
+}
+
+func TestLongestPrefixOfNoAlloc(t *testing.T) {
+	tree := NewTries()
+	for _, word := range []string{"a", "ab", "abc", "abcde"} {
+		tree.Insert(word)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		tree.LongestPrefixOf("abcdefghijk")
+	})
+	if allocs != 0 {
+		t.Errorf("expect no allocation, really %v", allocs)
+	}
+}
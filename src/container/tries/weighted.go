@@ -0,0 +1,180 @@
+package tries
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+)
+
+type weight struct {
+	score int64 // score of the word ends here
+	max   int64 // max score of words in the subtree
+	has   bool  // whether the subtree has any word, max is valid only if so
+}
+
+type Completion struct {
+	Word  string
+	Score int64
+}
+
+// Weighted is a trie whose words carry a score, every node caches the max
+// score below it so TopK only expands the most promising subtrees
+type Weighted struct {
+	node[weight]
+	size     int
+	alphabet Alphabet
+}
+
+func NewWeighted() *Weighted {
+	return NewWeightedWith(LowerCase)
+}
+
+func NewWeightedWith(a Alphabet) *Weighted {
+	return &Weighted{alphabet: a}
+}
+
+func (w *Weighted) Len() int {
+	return w.size
+}
+
+// fix recomputes max of n from its own score and children
+func (w *Weighted) fix(n *node[weight]) {
+	m, has := n.value.score, n.exists
+	for _, c := range n.children {
+		if c != nil && c.value.has && (!has || c.value.max > m) {
+			m, has = c.value.max, true
+		}
+	}
+	n.value.max, n.value.has = m, has
+}
+
+// Insert adds word with score, or updates score if word exists
+func (w *Weighted) Insert(word string, score int64) error {
+	if word == "" {
+		return errors.New("empty word")
+	}
+
+	if !w.alphabet.valid(word) {
+		return fmt.Errorf("%s has chars not allowed", word)
+	}
+
+	if w.put(&w.node, word, score) {
+		w.size += 1
+	}
+	return nil
+}
+
+func (w *Weighted) put(n *node[weight], word string, score int64) (added bool) {
+	if word == "" {
+		added = !n.exists
+		n.exists = true
+		n.value.score = score
+	} else {
+		c, size, _ := w.alphabet.next(word)
+		added = w.put(n.addChild(c, w.alphabet == LowerCase), word[size:], score)
+	}
+	w.fix(n)
+	return
+}
+
+func (w *Weighted) Score(word string) (score int64, ok bool) {
+	if word == "" {
+		return
+	}
+
+	if n := w.find(word, w.alphabet); n != nil && n.exists {
+		return n.value.score, true
+	}
+	return
+}
+
+func (w *Weighted) Match(word string) bool {
+	_, ok := w.Score(word)
+	return ok
+}
+
+// Delete removes word, reports whether it existed
+func (w *Weighted) Delete(word string) bool {
+	if word == "" {
+		return false
+	}
+
+	if w.remove(word, w.alphabet) == nil {
+		return false
+	}
+
+	w.refix(&w.node, word)
+	w.size -= 1
+	return true
+}
+
+// refix fixes max along what left of the path to word after pruning
+func (w *Weighted) refix(n *node[weight], word string) {
+	if word != "" {
+		c, size, _ := w.alphabet.next(word)
+		if child := n.child(c); child != nil {
+			w.refix(child, word[size:])
+		}
+	}
+	w.fix(n)
+}
+
+// candidate is either a word or a subtree yet to be expanded
+type candidate struct {
+	n     *node[weight]
+	word  string
+	score int64
+	leaf  bool
+}
+
+// candidates pops highest score first, then lexicographic smaller one,
+// a subtree sorts by its prefix which is not greater than any word in it
+type candidates []candidate
+
+func (h candidates) Len() int { return len(h) }
+func (h candidates) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score > h[j].score
+	}
+	if h[i].word != h[j].word {
+		return h[i].word < h[j].word
+	}
+	return h[i].leaf
+}
+func (h candidates) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *candidates) Push(x any)   { *h = append(*h, x.(candidate)) }
+func (h *candidates) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// TopK returns at most k words start with prefix with highest scores,
+// ordered by score descending then lexicographic order
+func (w *Weighted) TopK(prefix string, k int) (res []Completion) {
+	n := w.find(prefix, w.alphabet)
+	if n == nil || k <= 0 || !n.value.has {
+		return
+	}
+
+	h := &candidates{{n: n, word: prefix, score: n.value.max}}
+	for h.Len() > 0 && len(res) < k {
+		c := heap.Pop(h).(candidate)
+		if c.leaf {
+			res = append(res, Completion{c.word, c.score})
+			continue
+		}
+
+		if c.n.exists {
+			heap.Push(h, candidate{word: c.word, score: c.n.value.score, leaf: true})
+		}
+		for i, child := range c.n.children {
+			if child != nil {
+				word := string(w.alphabet.append([]byte(c.word), c.n.label(i)))
+				heap.Push(h, candidate{n: child, word: word, score: child.value.max})
+			}
+		}
+	}
+	return
+}
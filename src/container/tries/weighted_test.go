@@ -0,0 +1,135 @@
+package tries
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func bruteTopK(scores map[string]int64, prefix string, k int) (res []Completion) {
+	for word, score := range scores {
+		if strings.HasPrefix(word, prefix) {
+			res = append(res, Completion{word, score})
+		}
+	}
+	slices.SortFunc(res, func(x, y Completion) int {
+		if x.Score != y.Score {
+			if x.Score > y.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(x.Word, y.Word)
+	})
+	if len(res) > k {
+		res = res[:k]
+	}
+	return
+}
+
+func TestTopK(t *testing.T) {
+	w := NewWeighted()
+	scores := map[string]int64{"apple": 50, "app": 80, "application": 30, "apply": 50, "banana": 100, "ape": 10}
+	for word, score := range scores {
+		if err := w.Insert(word, score); err != nil {
+			t.Errorf("unexpected error %s", err.Error())
+		}
+	}
+
+	res := w.TopK("ap", 3)
+	if !slices.Equal(res, []Completion{{"app", 80}, {"apple", 50}, {"apply", 50}}) {
+		t.Errorf("not expected: %v", res)
+	}
+
+	// update score
+	w.Insert("application", 90)
+	if res := w.TopK("app", 1); !slices.Equal(res, []Completion{{"application", 90}}) {
+		t.Errorf("not expected: %v", res)
+	}
+	w.Insert("application", 1)
+	if res := w.TopK("app", 1); !slices.Equal(res, []Completion{{"app", 80}}) {
+		t.Errorf("not expected: %v", res)
+	}
+
+	if !w.Delete("app") || w.Delete("app") {
+		t.Error("delete app once expected")
+	}
+	if res := w.TopK("", 2); !slices.Equal(res, []Completion{{"banana", 100}, {"apple", 50}}) {
+		t.Errorf("not expected: %v", res)
+	}
+	if w.value.max != 100 {
+		t.Errorf("root max expect 100, really %d", w.value.max)
+	}
+
+	if res := w.TopK("x", 3); len(res) != 0 {
+		t.Errorf("not expected: %v", res)
+	}
+	if res := w.TopK("ap", 0); len(res) != 0 {
+		t.Errorf("not expected: %v", res)
+	}
+	if s, ok := w.Score("apply"); !ok || s != 50 {
+		t.Errorf("score of apply expect 50, really %d", s)
+	}
+	if w.Len() != len(scores)-1 {
+		t.Errorf("expect len %d, really %d", len(scores)-1, w.Len())
+	}
+}
+
+func TestTopKMinScore(t *testing.T) {
+	w := NewWeighted()
+	w.Insert("a", math.MinInt64)
+	if res := w.TopK("", 1); !slices.Equal(res, []Completion{{"a", math.MinInt64}}) {
+		t.Errorf("not expected: %v", res)
+	}
+	w.Insert("ab", math.MinInt64+1)
+	w.Delete("ab")
+	if res := w.TopK("a", 2); !slices.Equal(res, []Completion{{"a", math.MinInt64}}) {
+		t.Errorf("not expected: %v", res)
+	}
+	w.Delete("a")
+	if res := w.TopK("", 1); len(res) != 0 {
+		t.Errorf("not expected: %v", res)
+	}
+}
+
+func TestTopKRandom(t *testing.T) {
+	letters := []rune("abcd")
+	w := NewWeighted()
+	scores := map[string]int64{}
+	for i := 0; i < 1000; i++ {
+		word := randomWord(letters, 6)
+		switch rand.Intn(4) {
+		case 0:
+			delete(scores, word)
+			w.Delete(word)
+		default:
+			score := rand.Int63n(100) - 20
+			scores[word] = score
+			w.Insert(word, score)
+		}
+	}
+
+	if w.Len() != len(scores) {
+		t.Errorf("expect len %d, really %d", len(scores), w.Len())
+	}
+
+	for i := 0; i < 100; i++ {
+		prefix := randomWord(letters, 3)
+		if i%10 == 0 {
+			prefix = ""
+		}
+		k := 1 + rand.Intn(10)
+		if res, expect := w.TopK(prefix, k), bruteTopK(scores, prefix, k); !slices.Equal(res, expect) {
+			t.Errorf("top %d of %s expect %v, really %v", k, prefix, expect, res)
+		}
+	}
+
+	for word := range scores {
+		w.Delete(word)
+	}
+	if !w.empty() || len(w.TopK("", 1)) != 0 {
+		t.Error("should be empty")
+	}
+}